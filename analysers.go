@@ -3,8 +3,10 @@ package coi
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
 	"go/types"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
@@ -57,6 +59,15 @@ func FindFunctions(r *Runner) *analysis.Analyzer {
 	}
 }
 
+func FindFields(r *Runner) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:     "fields",
+		Doc:      "Collect struct fields of interest",
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run:      fieldsValues(r),
+	}
+}
+
 func FindStrings(r *Runner) *analysis.Analyzer {
 	return &analysis.Analyzer{
 		Name:     "strings",
@@ -67,58 +78,268 @@ func FindStrings(r *Runner) *analysis.Analyzer {
 }
 func stringValues(r *Runner) func(*analysis.Pass) (interface{}, error) {
 	return func(pass *analysis.Pass) (interface{}, error) {
-		inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+		walkStrings(pass, func(e ast.Expr, value string, stack []ast.Node) {
+			class := classify(value)
+			if !r.keepClass(class) {
+				return
+			}
+			item := Item{Category: "strings", Class: class, Role: stringRole(pass.TypesInfo, stack), Position: pass.Fset.Position(e.Pos())}
+			if _, isLit := e.(*ast.BasicLit); !isLit {
+				item.Parts = stringParts(e)
+			}
+			if detectSecret(value) != "" {
+				value, item.Hash, item.Parts = redact(value), r.secretHash(value), nil
+			}
+			item.Value = value
+
+			msg := fmt.Sprintf("string: %q", value) + item.details()
+			pass.Report(analysis.Diagnostic{
+				Category: "strings",
+				Pos:      e.Pos(),
+				Message:  msg,
+			})
+			r.report(pass, stack, item)
+		})
+
+		return nil, nil
+	}
+}
 
-		inspect.WithStack(nil, func(n ast.Node, push bool, stack []ast.Node) bool {
-			if lit, ok := n.(*ast.BasicLit); ok {
-				parent := stack[len(stack)-2]
-				if _, isImport := parent.(*ast.ImportSpec); !isImport {
-					if lit.Kind == token.STRING {
-						if lit.Value == `""` {
-							return false
-						}
-						pass.Report(analysis.Diagnostic{
-							Category: "strings",
-							Pos:      lit.ValuePos,
-							Message:  fmt.Sprintf("string: %s", lit.Value),
-						})
-						r.ReportChan <- NewStringItem(lit, pass.Fset)
-						return false
+// stringRole describes the syntactic role of the string expression at
+// the top of the stack: the constant or variable it initialises, struct
+// tag, map key or value, struct literal field, argument of a call,
+// error or log message, or return value. Conversions keep the role of
+// their operand. It returns an empty string for other roles.
+func stringRole(info *types.Info, stack []ast.Node) string {
+	child := stack[len(stack)-1]
+	for i := len(stack) - 2; i > 0; i-- {
+		switch parent := stack[i].(type) {
+		case *ast.ParenExpr:
+		case *ast.CallExpr:
+			if tv, ok := info.Types[parent.Fun]; ok && tv.IsType() {
+				break
+			}
+			for j, arg := range parent.Args {
+				if arg == child {
+					return callRole(info, parent, j)
+				}
+			}
+			return ""
+		case *ast.ValueSpec:
+			for j, v := range parent.Values {
+				if v == child && j < len(parent.Names) {
+					if decl, ok := stack[i-1].(*ast.GenDecl); ok && decl.Tok == token.CONST {
+						return "const " + parent.Names[j].Name
+					}
+					return "var " + parent.Names[j].Name
+				}
+			}
+			return ""
+		case *ast.AssignStmt:
+			for j, v := range parent.Rhs {
+				if v == child && len(parent.Lhs) == len(parent.Rhs) {
+					if parent.Tok == token.DEFINE {
+						return "var " + types.ExprString(parent.Lhs[j])
 					}
+					return "assign " + types.ExprString(parent.Lhs[j])
+				}
+			}
+			return ""
+		case *ast.Field:
+			if parent.Tag == child {
+				return "struct tag"
+			}
+			return ""
+		case *ast.KeyValueExpr:
+			lit, ok := stack[i-1].(*ast.CompositeLit)
+			if !ok {
+				return ""
+			}
+			t := info.TypeOf(lit)
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			switch t.Underlying().(type) {
+			case *types.Map:
+				if parent.Key == child {
+					return "map key"
+				}
+				return "map value"
+			case *types.Struct:
+				if key, ok := parent.Key.(*ast.Ident); ok && parent.Value == child {
+					return "field " + key.Name
 				}
 			}
+			return ""
+		case *ast.ReturnStmt:
+			return "return"
+		default:
+			return ""
+		}
+		child = stack[i]
+	}
+	return ""
+}
+
+// callRole describes the argument at index i of a call.
+func callRole(info *types.Info, call *ast.CallExpr, i int) string {
+	callee := calleeName(info, call.Fun)
+	switch {
+	case (callee == "errors.New" || callee == "fmt.Errorf") && i == 0:
+		return "error"
+	case strings.HasPrefix(callee, "log.") || strings.HasPrefix(callee, "log/slog."):
+		return "log"
+	}
+	return fmt.Sprintf("arg %d of %s", i, callee)
+}
+
+// calleeName returns the qualified name of the function or method
+// called, or its source when it is not a named function.
+func calleeName(info *types.Info, fun ast.Expr) string {
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	}
+	if fn, ok := info.Uses[id].(*types.Func); ok && fn.Pkg() != nil {
+		fn = fn.Origin()
+		if typ := declaringType(fn); typ != "" {
+			return typ + "." + fn.Name()
+		}
+		return fn.Pkg().Path() + "." + fn.Name()
+	}
+	return types.ExprString(fun)
+}
+
+// walkStrings calls f with every constant string expression of the
+// package that is not empty, import paths aside, and its folded value.
+// Expressions are not walked into once folded.
+func walkStrings(pass *analysis.Pass, f func(e ast.Expr, value string, stack []ast.Node)) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.WithStack(nil, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
 			return true
-		})
+		}
+		switch e := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case ast.Expr:
+			value, ok := foldedString(pass.TypesInfo, e)
+			if !ok {
+				return true
+			}
+			if value != "" {
+				f(e, value, stack)
+			}
+			return false
+		}
+		return true
+	})
+}
 
-		return nil, nil
+// foldedString returns the value of e when it is a constant string
+// expression built from at least one string literal, such as a typed
+// constant or a concatenation. Struct tags, which are not type
+// checked, are unquoted.
+func foldedString(info *types.Info, e ast.Expr) (string, bool) {
+	tv, ok := info.Types[e]
+	if !ok {
+		if lit, isLit := e.(*ast.BasicLit); isLit && lit.Kind == token.STRING {
+			s, err := strconv.Unquote(lit.Value)
+			return s, err == nil
+		}
+		return "", false
+	}
+	if tv.Value == nil || tv.Value.Kind() != constant.String || !hasStringLit(e) {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func hasStringLit(e ast.Expr) bool {
+	var found bool
+	ast.Inspect(e, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// stringParts returns the source of the operands a constant string
+// expression is built from.
+func stringParts(e ast.Expr) []string {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return stringParts(e.X)
+	case *ast.BinaryExpr:
+		return append(stringParts(e.X), stringParts(e.Y)...)
+	case *ast.CallExpr:
+		// Conversions to string types.
+		if len(e.Args) == 1 {
+			return stringParts(e.Args[0])
+		}
 	}
+	return []string{types.ExprString(e)}
 }
 
 func methods(r *Runner) func(*analysis.Pass) (interface{}, error) {
 	return func(pass *analysis.Pass) (interface{}, error) {
 		inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
-		inspect.WithStack(nil, func(n ast.Node, push bool, stack []ast.Node) bool {
-			if call, ok := n.(*ast.CallExpr); ok {
-				fun, _ := call.Fun.(*ast.SelectorExpr)
-				if fun != nil {
-					if t := pass.TypesInfo.Types[fun.X].Type; t != nil {
-						for _, m := range r.methods {
-							typ, method := m.left, m.right
-							if t.String() == typ {
-								if fun.Sel.Name == method {
-									msg := fmt.Sprintf("%s.%s(%s)", typ, method, argsAsCommaSeparatedValues(call.Args))
-									pass.Report(analysis.Diagnostic{
-										Pos:      call.Pos(),
-										Category: "methods",
-										Message:  msg,
-									})
-									r.ReportChan <- Item{Category: "methods", Value: msg, Position: pass.Fset.Position(call.Pos())}
-									return false
-								}
-							}
-						}
-					}
+		var ifaces [][]*types.TypeName
+		if r.matchInterfaces {
+			ifaces = interfacesOf(pass.Pkg, r.methods)
+		}
+
+		inspect.WithStack([]ast.Node{(*ast.SelectorExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if !push {
+				return true
+			}
+			fun := n.(*ast.SelectorExpr)
+			sel := pass.TypesInfo.Selections[fun]
+			if sel == nil || sel.Kind() == types.FieldVal {
+				return true
+			}
+			fn, _ := sel.Obj().(*types.Func)
+			typ := declaringType(fn)
+			if typ == "" {
+				return true
+			}
+			call := callOf(stack)
+			var args []ast.Expr
+			if call != nil {
+				args = call.Args
+				if sel.Kind() == types.MethodExpr && len(args) > 0 {
+					// Skip the receiver passed as first argument.
+					args = args[1:]
+				}
+			}
+			for i, m := range r.methods {
+				method := fn.Name()
+				if !m.matchRight(method) || !m.matchArgs(pass.TypesInfo, call, args) {
+					continue
+				}
+				match, via := "", ""
+				if m.matchLeft(typ) {
+					match = MatchDirect
+				} else if iface := implemented(sel.Recv(), method, ifaces, i); iface != nil {
+					match, via = MatchInterface, " via "+typeName(iface)
+				}
+				if match != "" {
+					symbol := typ + "." + method
+					kind, msg, hash := r.usage(pass.TypesInfo, symbol, call)
+					pass.Report(analysis.Diagnostic{
+						Pos:      fun.Pos(),
+						Category: "methods",
+						Message:  msg + via,
+					})
+					r.report(pass, stack, Item{Category: "methods", Pattern: m.String(), Symbol: symbol, Kind: kind, Match: match, Hash: hash, Value: msg, Position: pass.Fset.Position(fun.Pos())})
+					return true
 				}
 			}
 			return true
@@ -128,32 +349,165 @@ func methods(r *Runner) func(*analysis.Pass) (interface{}, error) {
 	}
 }
 
+// callOf returns the call of the function expression at the top of
+// the stack, or nil when the function is referenced as a value.
+func callOf(stack []ast.Node) *ast.CallExpr {
+	child := stack[len(stack)-1]
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch parent := stack[i].(type) {
+		case *ast.ParenExpr:
+		case *ast.IndexExpr:
+			if parent.X != child {
+				return nil
+			}
+		case *ast.IndexListExpr:
+			if parent.X != child {
+				return nil
+			}
+		case *ast.CallExpr:
+			if parent.Fun == child {
+				return parent
+			}
+			return nil
+		default:
+			return nil
+		}
+		child = stack[i]
+	}
+	return nil
+}
+
+// usage returns the kind of usage of the function symbol and its
+// description: the call with its arguments or the bare reference.
+// Secrets passed as arguments are redacted, hash being their HMAC.
+func (r *Runner) usage(info *types.Info, symbol string, call *ast.CallExpr) (kind, msg, hash string) {
+	if call == nil {
+		return KindReference, symbol, ""
+	}
+	args, secrets := argsAsCommaSeparatedValues(info, call.Args)
+	if len(secrets) > 0 {
+		hash = r.secretHash(strings.Join(secrets, "\x00"))
+	}
+	return KindCall, fmt.Sprintf("%s(%s)", symbol, args), hash
+}
+
+// declaringType returns the name of the type declaring the method fn,
+// such as net/http.Header, whatever the receiver it was selected from:
+// pointer, embedding struct, alias or generic instantiation.
+func declaringType(fn *types.Func) string {
+	if fn == nil {
+		return ""
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return ""
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return typeName(named.Origin().Obj())
+}
+
+// interfacesOf returns, for each method expression, the interface types
+// matching its left side among pkg and the packages it depends on.
+func interfacesOf(pkg *types.Package, exprs []Expr) [][]*types.TypeName {
+	ifaces := make([][]*types.TypeName, len(exprs))
+	seen := make(map[*types.Package]bool)
+	var visit func(p *types.Package)
+	visit = func(p *types.Package) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		scope := p.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !types.IsInterface(tn.Type()) {
+				continue
+			}
+			for i, e := range exprs {
+				if e.matchLeft(typeName(tn)) {
+					ifaces[i] = append(ifaces[i], tn)
+				}
+			}
+		}
+		for _, imp := range p.Imports() {
+			visit(imp)
+		}
+	}
+	visit(pkg)
+	return ifaces
+}
+
+// implemented returns the first interface found for the i-th method
+// expression that declares method and that t, or a pointer to t, implements.
+func implemented(t types.Type, method string, ifaces [][]*types.TypeName, i int) *types.TypeName {
+	if i >= len(ifaces) {
+		return nil
+	}
+	for _, tn := range ifaces[i] {
+		iface := tn.Type().Underlying().(*types.Interface)
+		if obj, _, _ := types.LookupFieldOrMethod(iface, false, nil, method); obj == nil {
+			continue
+		}
+		if types.Implements(t, iface) {
+			return tn
+		}
+		if _, isPtr := t.(*types.Pointer); !isPtr && !types.IsInterface(t) && types.Implements(types.NewPointer(t), iface) {
+			return tn
+		}
+	}
+	return nil
+}
+
+func typeName(tn *types.TypeName) string {
+	if tn.Pkg() == nil {
+		return tn.Name()
+	}
+	return tn.Pkg().Path() + "." + tn.Name()
+}
+
 func functions(r *Runner) func(*analysis.Pass) (interface{}, error) {
 	return func(pass *analysis.Pass) (interface{}, error) {
 		inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
-		inspect.WithStack(nil, func(n ast.Node, push bool, stack []ast.Node) bool {
-			if call, ok := n.(*ast.CallExpr); ok {
-				fun, _ := call.Fun.(*ast.SelectorExpr)
-				for _, f := range r.functions {
-					pkg, name := f.left, f.right
-					if fun != nil && fun.Sel != nil && fun.Sel.Name == name {
-						switch v := fun.X.(type) {
-						case *ast.Ident:
-							if p, isPackage := pass.TypesInfo.Uses[v].(*types.PkgName); isPackage {
-								if p.Imported().Path() == pkg {
-									msg := fmt.Sprintf("%s.%s(%s)", pkg, name, argsAsCommaSeparatedValues(call.Args))
-									pass.Report(analysis.Diagnostic{
-										Pos:      call.Pos(),
-										Category: "functions",
-										Message:  msg,
-									})
-									r.ReportChan <- Item{Category: "functions", Value: msg, Position: pass.Fset.Position(call.Pos())}
-									return false
-								}
-							}
-						}
-					}
+		inspect.WithStack([]ast.Node{(*ast.Ident)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if !push {
+				return true
+			}
+			fn, _ := pass.TypesInfo.Uses[n.(*ast.Ident)].(*types.Func)
+			if fn == nil || fn.Pkg() == nil || fn.Type().(*types.Signature).Recv() != nil {
+				return true
+			}
+			fn = fn.Origin()
+
+			// Qualified identifiers are reported as a whole.
+			var expr ast.Node = n
+			if sel, ok := stack[len(stack)-2].(*ast.SelectorExpr); ok && sel.Sel == n {
+				expr, stack = sel, stack[:len(stack)-1]
+			}
+
+			call := callOf(stack)
+			var args []ast.Expr
+			if call != nil {
+				args = call.Args
+			}
+			for _, f := range r.functions {
+				if f.matchLeft(fn.Pkg().Path()) && f.matchRight(fn.Name()) && f.matchArgs(pass.TypesInfo, call, args) {
+					symbol := fn.Pkg().Path() + "." + fn.Name()
+					kind, msg, hash := r.usage(pass.TypesInfo, symbol, call)
+					pass.Report(analysis.Diagnostic{
+						Pos:      expr.Pos(),
+						Category: "functions",
+						Message:  msg,
+					})
+					r.report(pass, stack, Item{Category: "functions", Pattern: f.String(), Symbol: symbol, Kind: kind, Hash: hash, Value: msg, Position: pass.Fset.Position(expr.Pos())})
+					return true
 				}
 			}
 			return true
@@ -167,29 +521,94 @@ func packagesValues(r *Runner) func(*analysis.Pass) (interface{}, error) {
 	return func(pass *analysis.Pass) (interface{}, error) {
 		inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
-		inspect.WithStack(nil, func(n ast.Node, push bool, stack []ast.Node) bool {
-			if call, ok := n.(*ast.CallExpr); ok {
-				fun, _ := call.Fun.(*ast.SelectorExpr)
-				for _, name := range r.packages {
-					if fun != nil && fun.X != nil {
-						switch v := fun.X.(type) {
-						case *ast.Ident:
-							if p, isPackage := pass.TypesInfo.Uses[v].(*types.PkgName); isPackage {
-								if p.Imported().Path() == name {
-									msg := fmt.Sprintf("%s.%s(%s)", name, fun.Sel.Name, argsAsCommaSeparatedValues(call.Args))
-									pass.Report(analysis.Diagnostic{
-										Pos:      call.Pos(),
-										Category: "packages",
-										Message:  msg,
-									})
-									r.ReportChan <- Item{Category: "packages", Value: msg, Position: pass.Fset.Position(call.Pos())}
-									return false
-								}
-							}
-						}
+		inspect.WithStack([]ast.Node{(*ast.Ident)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if !push {
+				return true
+			}
+			id := n.(*ast.Ident)
+			obj := pass.TypesInfo.Uses[id]
+			if obj == nil || obj.Pkg() == nil {
+				return true
+			}
+			object := objectKind(obj)
+			if object == "" {
+				return true
+			}
+			path := obj.Pkg().Path()
+			for _, name := range r.packages {
+				if path != name {
+					continue
+				}
+
+				// Qualified identifiers and selections are reported as a whole.
+				var expr ast.Node = id
+				if sel, ok := stack[len(stack)-2].(*ast.SelectorExpr); ok && sel.Sel == id {
+					expr, stack = sel, stack[:len(stack)-1]
+				}
+
+				symbol := objectSymbol(pass.TypesInfo, obj, stack)
+				var kind, msg, hash string
+				if _, ok := obj.(*types.Func); ok {
+					kind, msg, hash = r.usage(pass.TypesInfo, symbol, callOf(stack))
+				}
+				if kind != KindCall {
+					msg = fmt.Sprintf("%s (%s)", symbol, object)
+				}
+				pass.Report(analysis.Diagnostic{
+					Pos:      expr.Pos(),
+					Category: "packages",
+					Message:  msg,
+				})
+				r.report(pass, stack, Item{Category: "packages", Pattern: name, Symbol: symbol, Kind: kind, Object: object, Hash: hash, Value: msg, Position: pass.Fset.Position(expr.Pos())})
+				return true
+			}
+			return true
+		})
+
+		return nil, nil
+	}
+}
+
+func fieldsValues(r *Runner) func(*analysis.Pass) (interface{}, error) {
+	return func(pass *analysis.Pass) (interface{}, error) {
+		inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+		inspect.WithStack([]ast.Node{(*ast.Ident)(nil), (*ast.CompositeLit)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if !push {
+				return true
+			}
+			if lit, ok := n.(*ast.CompositeLit); ok {
+				// Unkeyed struct literals initialise every field by position.
+				t := pass.TypesInfo.TypeOf(lit)
+				if p, ok := t.(*types.Pointer); ok {
+					t = p.Elem()
+				}
+				st, ok := t.Underlying().(*types.Struct)
+				if !ok || len(lit.Elts) == 0 {
+					return true
+				}
+				if _, keyed := lit.Elts[0].(*ast.KeyValueExpr); keyed {
+					return true
+				}
+				for i, elt := range lit.Elts {
+					if i < st.NumFields() {
+						r.reportField(pass, stack, namedType(t), st.Field(i).Name(), FieldInit, elt, elt.Pos())
 					}
 				}
+				return true
 			}
+
+			id := n.(*ast.Ident)
+			v, _ := pass.TypesInfo.Uses[id].(*types.Var)
+			if v == nil || !v.IsField() {
+				return true
+			}
+			var expr ast.Expr = id
+			if sel, ok := stack[len(stack)-2].(*ast.SelectorExpr); ok && sel.Sel == id {
+				expr, stack = sel, stack[:len(stack)-1]
+			}
+			access, value := fieldAccess(stack, expr)
+			r.reportField(pass, stack, declaringStruct(pass.TypesInfo, stack), v.Name(), access, value, expr.Pos())
 			return true
 		})
 
@@ -197,10 +616,199 @@ func packagesValues(r *Runner) func(*analysis.Pass) (interface{}, error) {
 	}
 }
 
-func argsAsCommaSeparatedValues(args []ast.Expr) string {
-	var out []string
+func (r *Runner) reportField(pass *analysis.Pass, stack []ast.Node, owner, field, access string, value ast.Expr, pos token.Pos) {
+	if owner == "" {
+		return
+	}
+	for _, f := range r.fields {
+		if !f.matchLeft(owner) || !f.matchRight(field) {
+			continue
+		}
+		symbol := owner + "." + field
+		msg := symbol
+		var hash string
+		assigned := constantValue(pass.TypesInfo, value)
+		if value != nil {
+			if s := argSecret(pass.TypesInfo, value); s != "" {
+				assigned, hash = strconv.Quote(redact(s)), r.secretHash(s)
+			}
+		}
+		if assigned != "" {
+			msg += " = " + assigned
+		}
+		msg += " (" + access + ")"
+		pass.Report(analysis.Diagnostic{
+			Pos:      pos,
+			Category: "fields",
+			Message:  msg,
+		})
+		r.report(pass, stack, Item{Category: "fields", Pattern: f.String(), Symbol: symbol, Kind: access, Constant: assigned, Hash: hash, Value: msg, Position: pass.Fset.Position(pos)})
+		return
+	}
+}
+
+// fieldAccess tells whether the field expression at the top of the
+// stack is read, written or initialised in a struct literal, along
+// with the value it is given if any.
+func fieldAccess(stack []ast.Node, expr ast.Expr) (string, ast.Expr) {
+	switch parent := stack[len(stack)-2].(type) {
+	case *ast.KeyValueExpr:
+		if _, ok := stack[len(stack)-3].(*ast.CompositeLit); ok && parent.Key == expr {
+			return FieldInit, parent.Value
+		}
+	case *ast.AssignStmt:
+		for i, lhs := range parent.Lhs {
+			if lhs != expr {
+				continue
+			}
+			if parent.Tok == token.ASSIGN && len(parent.Lhs) == len(parent.Rhs) {
+				return FieldWrite, parent.Rhs[i]
+			}
+			return FieldWrite, nil
+		}
+	case *ast.IncDecStmt:
+		return FieldWrite, nil
+	}
+	return FieldRead, nil
+}
+
+// constantValue returns the constant value of e, strings being
+// quoted, or an empty string when e is not a constant.
+func constantValue(info *types.Info, e ast.Expr) string {
+	if e == nil {
+		return ""
+	}
+	tv, ok := info.Types[e]
+	if !ok || tv.Value == nil {
+		return ""
+	}
+	if tv.Value.Kind() == constant.String {
+		return strconv.Quote(constant.StringVal(tv.Value))
+	}
+	return tv.Value.String()
+}
+
+// objectKind returns the kind of a package level object or of a
+// member of one of its types, or an empty string for other objects.
+func objectKind(obj types.Object) string {
+	switch o := obj.(type) {
+	case *types.Func:
+		if o.Type().(*types.Signature).Recv() != nil {
+			return ObjectMethod
+		}
+		return ObjectFunc
+	case *types.TypeName:
+		return ObjectType
+	case *types.Const:
+		return ObjectConst
+	case *types.Var:
+		if o.IsField() {
+			return ObjectField
+		}
+		if o.Parent() == o.Pkg().Scope() {
+			return ObjectVar
+		}
+	}
+	return ""
+}
+
+// objectSymbol returns the qualified name of obj, used by the
+// identifier at the top of the stack. Methods and fields are
+// qualified by the type declaring them when it can be found.
+func objectSymbol(info *types.Info, obj types.Object, stack []ast.Node) string {
+	symbol := obj.Pkg().Path() + "." + obj.Name()
+	switch o := obj.(type) {
+	case *types.Func:
+		if typ := declaringType(o.Origin()); typ != "" {
+			symbol = typ + "." + o.Name()
+		}
+	case *types.Var:
+		if !o.IsField() {
+			break
+		}
+		if owner := declaringStruct(info, stack); owner != "" {
+			symbol = owner + "." + o.Name()
+		}
+	}
+	return symbol
+}
+
+// declaringStruct returns the name of the type declaring the field
+// used by the selector or the literal key at the top of the stack.
+func declaringStruct(info *types.Info, stack []ast.Node) string {
+	var owner types.Type
+	if sel, ok := stack[len(stack)-1].(*ast.SelectorExpr); ok {
+		if s := info.Selections[sel]; s != nil {
+			owner = fieldOwner(s.Recv(), s.Index())
+		}
+	} else if len(stack) > 2 {
+		// Keys of struct literals.
+		if lit, ok := stack[len(stack)-3].(*ast.CompositeLit); ok {
+			owner = info.TypeOf(lit)
+		}
+	}
+	return namedType(owner)
+}
+
+// namedType returns the name of t, or of the type t points to,
+// when it is a named type.
+func namedType(t types.Type) string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return typeName(named.Origin().Obj())
+	}
+	return ""
+}
+
+// fieldOwner returns the type declaring the field reached from t
+// through the index path of a selection, embedded fields included.
+func fieldOwner(t types.Type, index []int) types.Type {
+	for _, i := range index[:len(index)-1] {
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return nil
+		}
+		t = st.Field(i).Type()
+	}
+	return t
+}
+
+// argsAsCommaSeparatedValues returns the source of the arguments,
+// those holding a secret being redacted, along with the secrets.
+func argsAsCommaSeparatedValues(info *types.Info, args []ast.Expr) (string, []string) {
+	var out, secrets []string
 	for _, expr := range args {
+		if s := argSecret(info, expr); s != "" {
+			out = append(out, strconv.Quote(redact(s)))
+			secrets = append(secrets, s)
+			continue
+		}
 		out = append(out, types.ExprString(expr))
 	}
-	return strings.Join(out, ", ")
+	return strings.Join(out, ", "), secrets
+}
+
+// argSecret returns the secret held by the argument, either as a
+// constant string or as a literal in a larger expression.
+func argSecret(info *types.Info, e ast.Expr) string {
+	if tv, ok := info.Types[e]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		if v := constant.StringVal(tv.Value); detectSecret(v) != "" {
+			return v
+		}
+	}
+	var secret string
+	ast.Inspect(e, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && secret == "" {
+			if v, err := strconv.Unquote(lit.Value); err == nil && detectSecret(v) != "" {
+				secret = v
+			}
+		}
+		return secret == ""
+	})
+	return secret
 }
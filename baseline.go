@@ -0,0 +1,94 @@
+package coi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// BaselineVersion is the version of the baseline file format.
+const BaselineVersion = 1
+
+// Baseline records known items so that later runs only report new ones.
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry is a known item. Its fingerprint does not depend on
+// the line, which is only kept to help locating it.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Category    string `json:"category"`
+	Value       string `json:"value"`
+	File        string `json:"file"`
+	Function    string `json:"function,omitempty"`
+	Line        int    `json:"line"`
+}
+
+// NewBaseline records every item of the report.
+func NewBaseline(r *Report) *Baseline {
+	b := &Baseline{Version: BaselineVersion, Entries: []BaselineEntry{}}
+	for _, i := range r.Items() {
+		b.Entries = append(b.Entries, BaselineEntry{
+			Fingerprint: i.Fingerprint(),
+			Category:    i.Category,
+			Value:       i.Value,
+			File:        filepath.ToSlash(i.RelativeFilepath),
+			Function:    i.Function,
+			Line:        i.Position.Line,
+		})
+	}
+	return b
+}
+
+// ReadBaseline reads a baseline file written by Baseline.Write.
+func ReadBaseline(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := new(Baseline)
+	if err := json.NewDecoder(f).Decode(b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if b.Version != BaselineVersion {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d", path, b.Version)
+	}
+	return b, nil
+}
+
+// Write writes the baseline as JSON.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// Apply removes from the report the items known by the baseline and
+// records in Report.Resolved the entries that no longer show up.
+// Identical entries are matched as many times as they were recorded.
+func (b *Baseline) Apply(r *Report) {
+	known := make(map[string]int)
+	for _, e := range b.Entries {
+		known[e.Fingerprint]++
+	}
+	r.Filter(func(i Item) bool {
+		f := i.Fingerprint()
+		if known[f] > 0 {
+			known[f]--
+			return false
+		}
+		return true
+	})
+	for _, e := range b.Entries {
+		if known[e.Fingerprint] > 0 {
+			known[e.Fingerprint]--
+			r.Resolved = append(r.Resolved, e)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package coi
+
+import (
+	"bytes"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	item := func(value, function string, line int) Item {
+		return Item{
+			Category:         "functions",
+			Value:            value,
+			Function:         function,
+			RelativeFilepath: "main.go",
+			Position:         token.Position{Filename: "/src/main.go", Line: line},
+		}
+	}
+	old := &Report{Functions: []Item{
+		item(`os.ReadFile("a")`, "load", 10),
+		item(`os.ReadFile("a")`, "load", 12),
+		item(`os.Remove("b")`, "clean", 20),
+	}}
+
+	var buf bytes.Buffer
+	if err := NewBaseline(old).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	writeFile(t, path, buf.String())
+	baseline, err := ReadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines shifted, one call removed, one call added.
+	current := &Report{Functions: []Item{
+		item(`os.ReadFile("a")`, "load", 15),
+		item(`os.ReadFile("a")`, "load", 17),
+		item(`os.ReadFile("a")`, "load", 19),
+	}}
+	baseline.Apply(current)
+
+	if exp := []Item{item(`os.ReadFile("a")`, "load", 19)}; !reflect.DeepEqual(current.Functions, exp) {
+		t.Fatalf("got %+v, want %+v", current.Functions, exp)
+	}
+	if len(current.Resolved) != 1 || current.Resolved[0].Value != `os.Remove("b")` || current.Resolved[0].Function != "clean" {
+		t.Fatalf("unexpected resolved entries %+v", current.Resolved)
+	}
+}
@@ -0,0 +1,205 @@
+package coi
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Call graph algorithms used to find the entry points reaching
+// configured functions and methods.
+const (
+	CallGraphCHA = "cha"
+	CallGraphVTA = "vta"
+)
+
+func validCallGraph(mode string) bool {
+	switch mode {
+	case "", CallGraphCHA, CallGraphVTA:
+		return true
+	}
+	return false
+}
+
+// reachability reports, for every configured function and method,
+// the entry points of the initial packages that can reach it in the
+// call graph of the whole program, along with one shortest call path.
+// Entry points are main functions, init functions and exported
+// functions and methods. Arguments predicates are not evaluated.
+//
+// The initial packages must have been loaded with all their syntax.
+// Packages with type errors are left out of the call graph. It stops
+// early and returns the error of ctx when it is cancelled.
+func (r *Runner) reachability(ctx context.Context, initial []*packages.Package) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	prog, pkgs := ssautil.AllPackages(initial, ssa.InstantiateGenerics)
+	prog.Build()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cg := cha.CallGraph(prog)
+	if r.callGraph == CallGraphVTA {
+		cg = vta.CallGraph(ssautil.AllFunctions(prog), cg)
+	}
+	cg.DeleteSyntheticNodes()
+
+	roots := make(map[*ssa.Package]bool)
+	for _, p := range pkgs {
+		if p != nil {
+			roots[p] = true
+		}
+	}
+
+	nodes := make([]*callgraph.Node, 0, len(cg.Nodes))
+	for fn, n := range cg.Nodes {
+		if fn != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return lessNode(nodes[i], nodes[j]) })
+
+	for _, target := range nodes {
+		pattern, symbol := r.matchCallee(target.Func)
+		if pattern == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next := pathsTo(target)
+		for _, entry := range nodes {
+			edge, ok := next[entry]
+			if !ok || entry == target || !roots[entry.Func.Pkg] || !isEntryPoint(entry.Func) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			path := []string{entry.Func.String()}
+			for e := edge; e != nil; e = next[e.Callee] {
+				path = append(path, e.Callee.Func.String())
+			}
+
+			// Located at the call leading to the target.
+			pos := edge.Pos()
+			if !pos.IsValid() {
+				pos = entry.Func.Pos()
+			}
+			msg := fmt.Sprintf("%s reaches %s: %s", entry.Func, symbol, strings.Join(path, " -> "))
+			r.ReportChan <- Item{
+				Category: "reachability",
+				Analyser: "callgraph",
+				Package:  entry.Func.Pkg.Pkg.Path(),
+				Function: entryName(entry.Func),
+				Pattern:  pattern,
+				Symbol:   symbol,
+				Value:    msg,
+				Position: prog.Fset.Position(pos),
+			}
+		}
+	}
+	return nil
+}
+
+// matchCallee returns the pattern of the first function or method
+// expression matching fn, and the symbol of fn.
+func (r *Runner) matchCallee(fn *ssa.Function) (pattern, symbol string) {
+	obj, _ := fn.Object().(*types.Func)
+	if obj == nil || obj.Pkg() == nil {
+		return "", ""
+	}
+	obj = obj.Origin()
+	if typ := declaringType(obj); typ != "" {
+		for _, m := range r.methods {
+			if m.matchLeft(typ) && m.matchRight(obj.Name()) {
+				return m.String(), typ + "." + obj.Name()
+			}
+		}
+		return "", ""
+	}
+	if obj.Type().(*types.Signature).Recv() != nil {
+		return "", ""
+	}
+	for _, f := range r.functions {
+		if f.matchLeft(obj.Pkg().Path()) && f.matchRight(obj.Name()) {
+			return f.String(), obj.Pkg().Path() + "." + obj.Name()
+		}
+	}
+	return "", ""
+}
+
+// pathsTo walks the call graph backwards from target and returns, for
+// every node reaching it, the first edge of a shortest path to it.
+// The target maps to a nil edge.
+func pathsTo(target *callgraph.Node) map[*callgraph.Node]*callgraph.Edge {
+	next := map[*callgraph.Node]*callgraph.Edge{target: nil}
+	queue := []*callgraph.Node{target}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		in := append([]*callgraph.Edge(nil), n.In...)
+		sort.Slice(in, func(i, j int) bool {
+			if in[i].Caller != in[j].Caller {
+				return lessNode(in[i].Caller, in[j].Caller)
+			}
+			return in[i].Pos() < in[j].Pos()
+		})
+		for _, e := range in {
+			if _, seen := next[e.Caller]; !seen {
+				next[e.Caller] = e
+				queue = append(queue, e.Caller)
+			}
+		}
+	}
+	return next
+}
+
+// isEntryPoint reports whether fn can be called from outside of its
+// package: main.main, init functions and exported functions.
+func isEntryPoint(fn *ssa.Function) bool {
+	if fn.Parent() != nil || fn.Synthetic != "" || fn.Pkg == nil {
+		return false
+	}
+	if strings.HasPrefix(fn.Name(), "init#") {
+		return true
+	}
+	if fn.Pkg.Pkg.Name() == "main" {
+		return fn.Name() == "main" && fn.Signature.Recv() == nil
+	}
+	return token.IsExported(fn.Name())
+}
+
+// entryName returns the name of fn as recorded in Item.Function.
+func entryName(fn *ssa.Function) string {
+	name, _, _ := strings.Cut(fn.Name(), "#")
+	if recv := fn.Signature.Recv(); recv != nil {
+		t := recv.Type()
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		if named, ok := t.(*types.Named); ok {
+			name = named.Obj().Name() + "." + name
+		}
+	}
+	return name
+}
+
+func lessNode(a, b *callgraph.Node) bool {
+	if as, bs := a.Func.String(), b.Func.String(); as != bs {
+		return as < bs
+	}
+	return a.ID < b.ID
+}
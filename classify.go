@@ -0,0 +1,139 @@
+package coi
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Classes of strings recorded in Item.Class.
+const (
+	ClassURL      = "url"
+	ClassEmail    = "email"
+	ClassIP       = "ip"
+	ClassHostPort = "hostport"
+	ClassMIME     = "mime"
+	ClassTemplate = "template"
+	ClassJSON     = "json"
+	ClassYAML     = "yaml"
+	ClassSQL      = "sql"
+	ClassEnv      = "env"
+	ClassRegexp   = "regexp"
+	ClassPath     = "path"
+)
+
+// StringClasses lists the classes strings can be filtered on,
+// in the order they are tried.
+var StringClasses = []string{
+	ClassURL, ClassEmail, ClassIP, ClassHostPort, ClassMIME, ClassTemplate,
+	ClassJSON, ClassYAML, ClassSQL, ClassEnv, ClassRegexp, ClassPath,
+}
+
+var (
+	emailRegexp   = regexp.MustCompile(`^[\w.%+-]+@[\w-]+(\.[\w-]+)*\.[a-zA-Z]{2,}$`)
+	mimeRegexp    = regexp.MustCompile(`^(application|audio|font|image|message|model|multipart|text|video)/[\w.+-]+(\s*;.*)?$`)
+	yamlRegexp    = regexp.MustCompile(`(?m)^\s*(- )?[\w.-]+:(\s|$)`)
+	sqlRegexp     = regexp.MustCompile(`(?is)^\s*(SELECT\s.+\sFROM\s|INSERT\s+INTO\s|UPDATE\s+\S+\s+SET\s|DELETE\s+FROM\s|(CREATE|DROP|ALTER)\s+(TABLE|INDEX|VIEW)\s|WITH\s+\w+\s+AS\s*\()`)
+	envRegexp     = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)+$`)
+	regexpHints   = regexp.MustCompile(`^\^|\$$|\\[dDwWsSb]|\.[*+]|\[[^\]]+\][*+?{]|\(\?`)
+	pathRegexp    = regexp.MustCompile(`^(/|\./|\.\./|~/|[A-Za-z]:\\)`)
+	fileExtRegexp = regexp.MustCompile(`[/\\][\w.-]*\.\w{1,5}$`)
+)
+
+// classify returns the class of the string value, or an empty
+// string when it does not look like any of StringClasses.
+func classify(s string) string {
+	switch {
+	case isURL(s):
+		return ClassURL
+	case emailRegexp.MatchString(s):
+		return ClassEmail
+	case isIP(s):
+		return ClassIP
+	case isHostPort(s):
+		return ClassHostPort
+	case mimeRegexp.MatchString(s):
+		return ClassMIME
+	case isTemplate(s):
+		return ClassTemplate
+	case isJSON(s):
+		return ClassJSON
+	case strings.Contains(s, "\n") && len(yamlRegexp.FindAllString(s, 2)) == 2:
+		return ClassYAML
+	case sqlRegexp.MatchString(s):
+		return ClassSQL
+	case envRegexp.MatchString(s):
+		return ClassEnv
+	case isRegexp(s):
+		return ClassRegexp
+	case isPath(s):
+		return ClassPath
+	}
+	return ""
+}
+
+func validClass(c string) bool {
+	for _, class := range StringClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func isURL(s string) bool {
+	if strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && (u.Host != "" || u.Scheme == "file")
+}
+
+func isIP(s string) bool {
+	if net.ParseIP(s) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+func isHostPort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || strings.ContainsAny(host, " /") {
+		return false
+	}
+	_, err = strconv.ParseUint(port, 10, 16)
+	return err == nil
+}
+
+func isTemplate(s string) bool {
+	if !strings.Contains(s, "{{") || !strings.Contains(s, "}}") {
+		return false
+	}
+	_, err := template.New("").Parse(s)
+	return err == nil
+}
+
+func isJSON(s string) bool {
+	t := strings.TrimSpace(s)
+	return (strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[")) && json.Valid([]byte(t))
+}
+
+func isRegexp(s string) bool {
+	if !regexpHints.MatchString(s) {
+		return false
+	}
+	_, err := regexp.Compile(s)
+	return err == nil
+}
+
+func isPath(s string) bool {
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	return pathRegexp.MatchString(s) || fileExtRegexp.MatchString(s)
+}
@@ -0,0 +1,39 @@
+package coi
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tcases := []struct {
+		value, exp string
+	}{
+		{"https://example.com/v1/users", ClassURL},
+		{"file:///etc/hosts", ClassURL},
+		{"admin@example.com", ClassEmail},
+		{"10.0.0.1", ClassIP},
+		{"::1", ClassIP},
+		{"10.0.0.0/8", ClassIP},
+		{"localhost:8080", ClassHostPort},
+		{":443", ClassHostPort},
+		{"application/json", ClassMIME},
+		{"text/html; charset=utf-8", ClassMIME},
+		{"Hello {{.Name}}", ClassTemplate},
+		{`{"enabled": true}`, ClassJSON},
+		{"name: coi\nversion: 1\n", ClassYAML},
+		{"SELECT id FROM users WHERE name = ?", ClassSQL},
+		{"insert into users (id) values ($1)", ClassSQL},
+		{"DATABASE_URL", ClassEnv},
+		{`^\d+$`, ClassRegexp},
+		{`[a-z]+@`, ClassRegexp},
+		{"/etc/passwd", ClassPath},
+		{"config/app.yaml", ClassPath},
+		{`C:\temp`, ClassPath},
+		{"hello world", ""},
+		{"users", ""},
+		{"_", ""},
+	}
+	for _, tc := range tcases {
+		if got := classify(tc.value); got != tc.exp {
+			t.Errorf("classify(%q) = %q, want %q", tc.value, got, tc.exp)
+		}
+	}
+}
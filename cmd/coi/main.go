@@ -1,9 +1,28 @@
+// Command coi collects code of interest in Go packages.
+//
+// Exit codes let CI pipelines tell findings apart from tool failures:
+//
+//	0  success, no gate condition violated
+//	1  tool failure: invalid configuration, load or analysis errors
+//	2  invalid command line flags
+//	3  findings violating a gate condition (-fail, -max, -fail-on)
+//
+// "coi baseline write [flags]" saves the current findings to the
+// baseline file so that later runs with -baseline only report new ones.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 
 	"golang.org/x/mod/modfile"
 
@@ -15,16 +34,114 @@ var (
 	htmlFormatFlag         bool
 	analyserFlag           string
 	packagesFlag           string
-	packagesAnalyserValues []string
+	configFlag             string
+	formatFlag             formatValue
+	linksFlag              string
+	stringsFlag            bool
+	classFlag              listFlag
+	secretsFlag            bool
+	methodsFlag            listFlag
+	functionsFlag          listFlag
+	fieldsFlag             listFlag
+	tagsFlag               listFlag
+	packagesAnalyserValues listFlag
+	importsFlag            listFlag
+	failFlag               bool
+	maxFlag                = make(maxValues)
+	failOnFlag             listFlag
+	baselineFlag           string
+	sinceFlag              string
+	interfacesFlag         bool
+	callGraphFlag          string
 )
 
+const defaultBaselineFile = ".coi-baseline.json"
+
+const (
+	exitSuccess  = 0
+	exitFailure  = 1
+	exitFindings = 3 // 2 is used by the flag package
+)
+
+// listFlag is a flag that can be repeated to collect several values.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// maxValues collects the category=count maximums of -max, invalid
+// values making the flag package exit with code 2.
+type maxValues map[string]int
+
+func (m maxValues) String() string {
+	var values []string
+	for category, n := range m {
+		values = append(values, fmt.Sprintf("%s=%d", category, n))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+func (m maxValues) Set(v string) error {
+	category, count, _ := strings.Cut(v, "=")
+	n, err := strconv.Atoi(count)
+	if err != nil || !slices.Contains(coi.CategoryNames, category) {
+		return fmt.Errorf("want category=count with category one of %s", strings.Join(coi.CategoryNames, ", "))
+	}
+	m[category] = n
+	return nil
+}
+
+// formatValue is a report format validated when parsing flags.
+type formatValue string
+
+func (f *formatValue) String() string { return string(*f) }
+
+func (f *formatValue) Set(v string) error {
+	switch v {
+	case "text", "html", "json", "sarif":
+		*f = formatValue(v)
+		return nil
+	}
+	return fmt.Errorf("unknown format %q: want text, html, json or sarif", v)
+}
+
 func main() {
 	log.SetFlags(0)
 	flag.BoolVar(&printPositionsFlag, "pos", false, "Print filename position for each value")
 	flag.BoolVar(&htmlFormatFlag, "html", false, "Generate HTML report file coi.html")
-	flag.StringVar(&analyserFlag, "a", "", "Which analyser to run")
+	flag.Var(&formatFlag, "format", "Report format: text, html, json or sarif")
+	flag.StringVar(&analyserFlag, "a", "", "Which analyser to run with arguments (s, m, f or p)")
+	flag.BoolVar(&stringsFlag, "s", false, "Collect literal strings")
+	flag.Var(&classFlag, "class", "Only collect strings of the given class such as url or sql (repeatable)")
+	flag.BoolVar(&secretsFlag, "secrets", false, "Collect hard-coded secrets, redacted in reports")
+	flag.Var(&methodsFlag, "m", "Method to collect such as net/http.Header.Set (repeatable)")
+	flag.BoolVar(&interfacesFlag, "interfaces", false, "Also match methods called on types implementing a configured interface")
+	flag.StringVar(&callGraphFlag, "callgraph", "", "Also report entry points reaching configured functions and methods, using the cha or vta call graph")
+	flag.Var(&functionsFlag, "f", "Function to collect such as os.ReadFile (repeatable)")
+	flag.Var(&fieldsFlag, "field", "Struct field to collect such as crypto/tls.Config.InsecureSkipVerify (repeatable)")
+	flag.Var(&tagsFlag, "tag", "Struct tag key to collect such as json, or * for all keys (repeatable)")
+	flag.Var(&packagesAnalyserValues, "pkg", "Package whose usage to collect such as encoding/hex (repeatable)")
+	flag.Var(&importsFlag, "imp", "Package whose direct and transitive imports to collect such as net/http (repeatable)")
 	flag.StringVar(&packagesFlag, "p", "./...", "Which packages ro tun on")
-	flag.Parse()
+	flag.StringVar(&linksFlag, "links", "", "Source link provider: github, gitlab, bitbucket, gitea or vscode")
+	flag.StringVar(&configFlag, "config", "", "Load analysers and output settings from YAML config file")
+	flag.BoolVar(&failFlag, "fail", false, "Exit with code 3 when there are findings")
+	flag.Var(maxFlag, "max", "Exit with code 3 when a category has more findings, as category=count (repeatable)")
+	flag.Var(&failOnFlag, "fail-on", "Exit with code 3 when the given pattern matched (repeatable)")
+	flag.StringVar(&baselineFlag, "baseline", "", "Only report findings missing from the given baseline file")
+	flag.StringVar(&sinceFlag, "since", "", "Only report findings on lines changed since the given git ref, such as origin/main")
+
+	args := os.Args[1:]
+	var writeBaseline bool
+	if len(args) >= 2 && args[0] == "baseline" && args[1] == "write" {
+		writeBaseline, args = true, args[2:]
+	}
+	flag.CommandLine.Parse(args)
 
 	dir, err := os.Getwd()
 	if err != nil {
@@ -34,40 +151,184 @@ func main() {
 	if err != nil {
 		log.Println(err)
 	}
-	config := coi.Config{Module: modfile.ModulePath(f), WorkingDir: dir}
 
-	var all []coi.AnalyserFunc
+	var config coi.Config
+	if configFlag != "" {
+		if config, err = coi.LoadConfig(configFlag); err != nil {
+			log.Fatal(err)
+		}
+	}
+	config.Module = modfile.ModulePath(f)
+	config.WorkingDir = dir
+
+	if baselineFlag != "" {
+		config.Baseline = baselineFlag
+	}
+	if sinceFlag != "" {
+		config.Since = sinceFlag
+	}
+	if callGraphFlag != "" {
+		config.CallGraph = callGraphFlag
+	}
+	if linksFlag != "" {
+		config.Links.Provider = linksFlag
+	}
+	config.Strings = config.Strings || stringsFlag
+	config.StringClasses = append(config.StringClasses, classFlag...)
+	config.Secrets = config.Secrets || secretsFlag
+	if salt := os.Getenv("COI_SECRETS_SALT"); salt != "" {
+		config.SecretsSalt = salt
+	}
+	if config.Secrets && config.SecretsSalt == "" {
+		log.Println("warning: COI_SECRETS_SALT is not set, secret hashes are keyed with the public module path")
+	}
+	config.MatchInterfaces = config.MatchInterfaces || interfacesFlag
+	config.Methods = append(config.Methods, methodsFlag...)
+	config.Functions = append(config.Functions, functionsFlag...)
+	config.Fields = append(config.Fields, fieldsFlag...)
+	config.Tags = append(config.Tags, tagsFlag...)
+	config.Packages = append(config.Packages, packagesAnalyserValues...)
+	config.Imports = append(config.Imports, importsFlag...)
+	config.Gate.FailOnFindings = config.Gate.FailOnFindings || failFlag
+	config.Gate.FailOn = append(config.Gate.FailOn, failOnFlag...)
+	for category, n := range maxFlag {
+		if config.Gate.Max == nil {
+			config.Gate.Max = make(map[string]int)
+		}
+		config.Gate.Max[category] = n
+	}
+
 	switch analyserFlag {
 	case "s":
-		all = append(all, coi.FindStrings)
+		config.Strings = true
 	case "p":
-		config.Packages = flag.Args()
-		all = append(all, coi.FindPackages)
+		config.Packages = append(config.Packages, flag.Args()...)
 	case "m":
 		config.Methods = append(config.Methods, flag.Args()...)
-		all = append(all, coi.FindMethods)
 	case "f":
 		config.Functions = append(config.Functions, flag.Args()...)
-		all = append(all, coi.FindFunctions)
+	}
+	for _, p := range failOnFlag {
+		if !slices.Contains(config.Patterns(), p) {
+			fmt.Fprintf(os.Stderr, "invalid value %q for flag -fail-on: pattern is not configured\n", p)
+			flag.Usage()
+			os.Exit(2)
+		}
 	}
 
-	runner, err := coi.NewAnalysis(config, all...)
+	runner, err := coi.NewAnalysis(config, config.Analysers()...)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	go func() {
-		runner.Run([]string{packagesFlag})
-	}()
-	report := coi.BuildReport(runner)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	report, runErr := runner.RunContext(ctx, []string{packagesFlag})
+	stop()
+	if runErr != nil {
+		log.Println(runErr)
+	}
+
+	if writeBaseline {
+		name := config.Baseline
+		if name == "" {
+			name = defaultBaselineFile
+		}
+		if runErr != nil {
+			// A partial baseline would report known findings as new.
+			log.Fatalf("baseline not written to %s: the run failed", name)
+		}
+		if err := writeBaselineFile(report, name); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(exitSuccess)
+	}
+	if config.Baseline != "" {
+		baseline, err := coi.ReadBaseline(config.Baseline)
+		if err != nil {
+			log.Fatal(err)
+		}
+		baseline.Apply(report)
+	}
+	if config.Since != "" {
+		changed, err := coi.ChangesSince(dir, config.Since)
+		if err != nil {
+			log.Fatal(err)
+		}
+		report.Filter(func(i coi.Item) bool {
+			return changed.Contains(i.RelativeFilepath, i.Position.Line)
+		})
+	}
 
+	format := config.Output.Format
+	if formatFlag != "" {
+		format = string(formatFlag)
+	}
 	if htmlFormatFlag {
-		f, err := os.Create("coi.html")
+		format = "html"
+	}
+	if err := writeReport(report, format, config.Output.File); err != nil {
+		log.Fatal(err)
+	}
+
+	if runErr != nil {
+		os.Exit(exitFailure)
+	}
+	if !config.Gate.Enabled() {
+		os.Exit(exitSuccess)
+	}
+	if violations := config.Gate.Check(report); len(violations) > 0 {
+		for _, v := range violations {
+			log.Printf("gate: %s", v)
+		}
+		os.Exit(exitFindings)
+	}
+	os.Exit(exitSuccess)
+}
+
+func writeBaselineFile(report *coi.Report, name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := coi.NewBaseline(report).Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeReport(report *coi.Report, format, name string) error {
+	if format == "html" && name == "" {
+		name = "coi.html"
+	}
+	var w io.WriteCloser = os.Stdout
+	if name != "" {
+		f, err := os.Create(name)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		report.ToHTML(f)
-	} else {
-		report.ToText(os.Stdout)
+		w = f
+	}
+
+	switch format {
+	case "html":
+		report.ToHTML(w)
+		return nil
+	case "json":
+		if err := report.ToJSON(w); err != nil {
+			return err
+		}
+	case "sarif":
+		if err := report.ToSARIF(w); err != nil {
+			return err
+		}
+	case "", "text":
+		report.ToText(w)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+	if w != os.Stdout {
+		return w.Close()
 	}
+	return nil
 }
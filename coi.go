@@ -1,22 +1,44 @@
 package coi
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"golang.org/x/tools/go/analysis"
 )
 
 type Config struct {
-	Module           string
-	WorkingDir       string
-	PrintDiagnostics bool
+	Module           string   `yaml:"-"`
+	WorkingDir       string   `yaml:"-"`
+	PrintDiagnostics bool     `yaml:"-"`
+	Version          int      `yaml:"version"`
+	Strings          bool     `yaml:"strings"`
+	StringClasses    []string `yaml:"string_classes"`
+	Secrets          bool     `yaml:"secrets"`
+	SecretsSalt      string   `yaml:"secrets_salt"`
 	Methods          []string `yaml:"methods"`
 	Functions        []string `yaml:"functions"`
+	Fields           []string `yaml:"fields"`
+	Tags             []string `yaml:"tags"`
 	Packages         []string `yaml:"packages"`
+	Imports          []string `yaml:"imports"`
+	Include          []string `yaml:"include"`
+	Exclude          []string `yaml:"exclude"`
+	Links            Links    `yaml:"links"`
+	Gate             Gate     `yaml:"gate"`
+	Baseline         string   `yaml:"baseline"`
+	Since            string   `yaml:"since"`
+	MatchInterfaces  bool     `yaml:"match_interfaces"`
+	CallGraph        string   `yaml:"call_graph"`
+	Output           Output   `yaml:"output"`
 }
 
 type Runner struct {
@@ -26,46 +48,356 @@ type Runner struct {
 	analysers  []*analysis.Analyzer
 	methods    []Expr
 	functions  []Expr
+	fields     []Expr
 	packages   []string
+	classes    []string
+	tags       []string
+	imports    []string
+	include    []string
+	exclude    []string
+	links      *linker
+
+	// importGraph maps loaded packages to their imports,
+	// it is set once packages are loaded.
+	importGraph map[string][]string
+
+	matchInterfaces bool
+	callGraph       string
+	secretsSalt     string
 }
 
 type Item struct {
 	Category         string
 	Position         token.Position
 	RelativeFilepath string
-	GithubLink       string
-	Value            string
+	Link             string
+	Module           string
+	Package          string
+	Analyser         string
+	Pattern          string
+	Symbol           string
+	Kind             string
+	Match            string
+	Object           string
+	Constant         string
+	// Hash is a salted hash of a redacted secret value.
+	Hash string
+	// Role is the syntactic role of a string, such as "const Name",
+	// "struct tag", "map key" or "arg 0 of os.Getenv".
+	Role string
+	// Class is the kind of value a string looks like, see StringClasses.
+	Class string
+	// TagKey, TagName and TagOptions are the parsed key and value of
+	// a struct tag.
+	TagKey     string
+	TagName    string
+	TagOptions []string
+	// Inconsistent lists the other naming keys of a struct tag giving
+	// the field a different name, as key=name.
+	Inconsistent []string
+	// Parts lists the operands a constant string was folded from.
+	Parts    []string
+	Function string
+	Value    string
+
+	// Deprecated: GithubLink is set to Link for compatibility, use Link.
+	GithubLink string
+}
+
+// Kinds of function and method usages recorded in Item.Kind.
+// A reference is a function value or method expression that usually
+// leads to an indirect call.
+const (
+	KindCall      = "call"
+	KindReference = "reference"
+)
+
+// Kinds of method matches recorded in Item.Match.
+const (
+	MatchDirect    = "direct"
+	MatchInterface = "via interface"
+)
+
+// Kinds of field accesses recorded in Item.Kind. The constant value
+// given to a field, if any, is recorded in Item.Constant.
+const (
+	FieldRead  = "read"
+	FieldWrite = "write"
+	FieldInit  = "init"
+)
+
+// Kinds of package objects recorded in Item.Object.
+const (
+	ObjectFunc   = "func"
+	ObjectMethod = "method"
+	ObjectType   = "type"
+	ObjectVar    = "var"
+	ObjectConst  = "const"
+	ObjectField  = "field"
+)
+
+// Rule is a pattern configured for a category of analysis.
+// Literal strings have a single rule with an empty pattern.
+type Rule struct {
+	Category string
+	Pattern  string
+}
+
+// ID returns the stable identifier of the rule.
+func (r Rule) ID() string {
+	if r.Pattern == "" {
+		return r.Category
+	}
+	return r.Category + ":" + r.Pattern
 }
 
+// Rule returns the rule that produced the item.
+func (i Item) Rule() Rule { return Rule{Category: i.Category, Pattern: i.Pattern} }
+
+// details returns the class and role of the item, how a method was
+// matched or the tags it is inconsistent with, to print after its
+// value, such as ` (url) [arg 0 of net/http.Get]`.
+func (i Item) details() string {
+	var s string
+	if i.Class != "" {
+		s += " (" + i.Class + ")"
+	}
+	if i.Match != "" {
+		s += " (" + i.Match + ")"
+	}
+	if i.Role != "" {
+		s += " [" + i.Role + "]"
+	}
+	if len(i.Inconsistent) > 0 {
+		s += " inconsistent with " + strings.Join(i.Inconsistent, ", ")
+	}
+	return s
+}
+
+// Expr is a method or function specification split at its last dot,
+// such as net/http.Header and Set. Both sides can be patterns where *
+// matches any sequence of characters, ? a single character and (a|b)
+// alternatives, for instance net/http.*.Set* or os.(Read|Write)File.
+//
+// Arguments predicates can follow, separated by spaces, to only match
+// some calls. See parsePredicate for their syntax.
 type Expr struct {
-	left  string
-	right string
+	spec    string
+	left    string
+	right   string
+	leftRe  *regexp.Regexp
+	rightRe *regexp.Regexp
+	args    []argPredicate
 }
 
 func NewRunner(c Config) (*Runner, error) {
 	run := &Runner{
 		ReportChan: make(chan Item),
 		packages:   c.Packages,
+		imports:    c.Imports,
+		classes:    c.StringClasses,
+		tags:       c.Tags,
 		module:     c.Module,
 		workingDir: c.WorkingDir,
+		include:    c.Include,
+		exclude:    c.Exclude,
+
+		matchInterfaces: c.MatchInterfaces,
+		callGraph:       c.CallGraph,
+		secretsSalt:     c.SecretsSalt,
+	}
+	for _, class := range c.StringClasses {
+		if !validClass(class) {
+			return run, fmt.Errorf("unknown string class: %s", class)
+		}
+	}
+	if !validCallGraph(c.CallGraph) {
+		return run, fmt.Errorf("unknown call graph algorithm: %s", c.CallGraph)
+	}
+	links, err := newLinker(c.Links, c.Module, c.WorkingDir)
+	if err != nil {
+		return run, err
 	}
+	run.links = links
 	for _, m := range c.Methods {
-		if i := strings.LastIndex(m, "."); i > 0 {
-			run.methods = append(run.methods, Expr{m[:i], m[i+1:]})
-		} else {
-			return run, fmt.Errorf("invalid method format: %s", m)
+		e, err := parseExpr(m)
+		if err != nil {
+			return run, fmt.Errorf("invalid method format: %s: %w", m, err)
 		}
+		run.methods = append(run.methods, e)
 	}
 	for _, m := range c.Functions {
-		if i := strings.LastIndex(m, "."); i > 0 {
-			run.functions = append(run.functions, Expr{m[:i], m[i+1:]})
-		} else {
-			return run, fmt.Errorf("invalid function format: %s", m)
+		e, err := parseExpr(m)
+		if err != nil {
+			return run, fmt.Errorf("invalid function format: %s: %w", m, err)
 		}
+		run.functions = append(run.functions, e)
+	}
+	for _, f := range c.Fields {
+		e, err := parseField(f)
+		if err != nil {
+			return run, fmt.Errorf("invalid field format: %s: %w", f, err)
+		}
+		run.fields = append(run.fields, e)
 	}
 	return run, nil
 }
 
+func (e Expr) String() string { return e.spec }
+
+func parseExpr(s string) (Expr, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return Expr{}, errors.New("missing dot separated name")
+	}
+	name := fields[0]
+	i := strings.LastIndex(name, ".")
+	if i <= 0 || i == len(name)-1 {
+		return Expr{}, errors.New("missing dot separated name")
+	}
+	e := Expr{spec: strings.Join(fields, " "), left: name[:i], right: name[i+1:]}
+	if len(e.left) > 1 && e.left[0] == '*' && strings.IndexFunc(e.left[1:2], unicode.IsLetter) == 0 && strings.Contains(e.left, ".") {
+		// A pointer receiver such as *bytes.Buffer, whose methods are
+		// declared on the named type. Unqualified names such as *Client
+		// are patterns matching the type in any package.
+		e.left = e.left[1:]
+	}
+	for _, f := range fields[1:] {
+		p, err := parsePredicate(f)
+		if err != nil {
+			return e, err
+		}
+		e.args = append(e.args, p)
+	}
+	var err error
+	if e.leftRe, err = compilePattern(e.left); err != nil {
+		return e, err
+	}
+	if e.rightRe, err = compilePattern(e.right); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// parseField parses a field expression such as net/http.Request.Header,
+// which does not take arguments predicates.
+func parseField(s string) (Expr, error) {
+	e, err := parseExpr(s)
+	if err == nil && len(e.args) > 0 {
+		err = errors.New("unexpected arguments predicates")
+	}
+	return e, err
+}
+
+// compilePattern returns the regular expression of a pattern,
+// or nil when p is a plain name to compare exactly.
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if !strings.ContainsAny(p, "*?(|") {
+		return nil, nil
+	}
+	var b strings.Builder
+	b.WriteString("^(?:")
+	for _, c := range p {
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '(', ')', '|':
+			b.WriteRune(c)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString(")$")
+	return regexp.Compile(b.String())
+}
+
+// matchLeft reports whether the type or package s matches the expression.
+func (e Expr) matchLeft(s string) bool {
+	if e.leftRe != nil {
+		return e.leftRe.MatchString(s)
+	}
+	return s == e.left
+}
+
+// matchRight reports whether the method or function name s matches the expression.
+func (e Expr) matchRight(s string) bool {
+	if e.rightRe != nil {
+		return e.rightRe.MatchString(s)
+	}
+	return s == e.right
+}
+
+// Rules returns the rules configured for the runner analysers,
+// one for each method, function or package pattern.
+func (r *Runner) Rules() []Rule {
+	var rules []Rule
+	for _, a := range r.analysers {
+		if a.Name == "strings" || a.Name == "secrets" {
+			rules = append(rules, Rule{Category: a.Name})
+		}
+	}
+	for _, m := range r.methods {
+		rules = append(rules, Rule{Category: "methods", Pattern: m.String()})
+	}
+	for _, f := range r.functions {
+		rules = append(rules, Rule{Category: "functions", Pattern: f.String()})
+	}
+	for _, f := range r.fields {
+		rules = append(rules, Rule{Category: "fields", Pattern: f.String()})
+	}
+	for _, t := range r.tags {
+		rules = append(rules, Rule{Category: "tags", Pattern: t})
+	}
+	for _, p := range r.packages {
+		rules = append(rules, Rule{Category: "packages", Pattern: p})
+	}
+	if r.callGraph != "" {
+		for _, exprs := range [][]Expr{r.methods, r.functions} {
+			for _, e := range exprs {
+				rules = append(rules, Rule{Category: "reachability", Pattern: e.String()})
+			}
+		}
+	}
+	for _, p := range r.imports {
+		rules = append(rules, Rule{Category: "imports", Pattern: p})
+	}
+	return rules
+}
+
+// keep reports whether findings in the relative file path rel
+// pass the include and exclude filters.
+func (r *Runner) keep(rel string) bool {
+	for _, p := range r.exclude {
+		if matchPath(p, rel) {
+			return false
+		}
+	}
+	if len(r.include) == 0 {
+		return true
+	}
+	for _, p := range r.include {
+		if matchPath(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// keepClass reports whether strings of the class are collected.
+func (r *Runner) keepClass(class string) bool {
+	if len(r.classes) == 0 {
+		return true
+	}
+	for _, c := range r.classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Runner) GetRelativeFilepath(i Item) string {
 	rel, _ := filepath.Rel(r.workingDir, i.Position.Filename)
 	return rel
@@ -73,6 +405,63 @@ func (r *Runner) GetRelativeFilepath(i Item) string {
 
 func (r *Runner) Close() { close(r.ReportChan) }
 
+// report sends the item found by the pass, with the given
+// ancestors stack, to the report channel.
+func (r *Runner) report(pass *analysis.Pass, stack []ast.Node, item Item) {
+	item.Analyser = pass.Analyzer.Name
+	item.Package = pass.Pkg.Path()
+	item.Function = enclosingFunction(stack)
+	r.ReportChan <- item
+}
+
+// enclosingFunction returns the name of the function declaration
+// in the stack, such as "Type.Method", or an empty string at package
+// level. Function literals add a ".func" suffix.
+func enclosingFunction(stack []ast.Node) string {
+	var name string
+	for _, n := range stack {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			name = n.Name.Name
+			if n.Recv != nil && len(n.Recv.List) > 0 {
+				name = receiverName(n.Recv.List[0].Type) + "." + name
+			}
+		case *ast.FuncLit:
+			name += ".func"
+		}
+	}
+	return strings.TrimPrefix(name, ".")
+}
+
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return types.ExprString(expr)
+}
+
+// Fingerprint identifies the item regardless of its line, so that it
+// survives unrelated edits of the file.
+func (i Item) Fingerprint() string {
+	fields := []string{i.Category, i.Value, i.Function, filepath.ToSlash(i.RelativeFilepath)}
+	if i.Hash != "" {
+		// Redacted values of different secrets can be identical.
+		fields = append(fields, i.Hash)
+	}
+	return fingerprint(fields...)
+}
+
 func NewStringItem(l *ast.BasicLit, set *token.FileSet) Item {
-	return Item{Category: "strings", Value: l.Value, Position: set.Position(l.Pos())}
+	value, err := strconv.Unquote(l.Value)
+	if err != nil {
+		value = l.Value
+	}
+	return Item{Category: "strings", Value: value, Position: set.Position(l.Pos())}
 }
@@ -1,6 +1,9 @@
 package coi
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
@@ -14,6 +17,19 @@ func TestCollect(t *testing.T) {
 		analysistest.Run(t, data, analyser, "s")
 	})
 
+	t.Run("string roles", func(t *testing.T) {
+		analysistest.Run(t, data, FindStrings(mustNewRun(t, Config{Strings: true})), "role")
+	})
+
+	t.Run("string classes", func(t *testing.T) {
+		config := Config{Strings: true, StringClasses: []string{ClassURL, ClassSQL}}
+		analysistest.Run(t, data, FindStrings(mustNewRun(t, config)), "cls")
+	})
+
+	t.Run("secrets", func(t *testing.T) {
+		analysistest.Run(t, data, FindSecrets(mustNewRun(t, Config{Secrets: true})), "sec")
+	})
+
 	t.Run("methods", func(t *testing.T) {
 		config := Config{Methods: []string{"net/http.Header.Set", "net/http.Header.Add"}}
 		analyser := FindMethods(mustNewRun(t, config))
@@ -26,11 +42,77 @@ func TestCollect(t *testing.T) {
 		analysistest.Run(t, data, analyser, "o")
 	})
 
+	t.Run("patterns", func(t *testing.T) {
+		config := Config{
+			Methods:   []string{"net/http.*.(Set|Val*)", "*bytes.Buffer.Write*"},
+			Functions: []string{"os.(Read|Write)File"},
+		}
+		analysistest.Run(t, data, FindMethods(mustNewRun(t, config)), "gm")
+		analysistest.Run(t, data, FindFunctions(mustNewRun(t, config)), "gf")
+	})
+
+	t.Run("selections", func(t *testing.T) {
+		config := Config{Methods: []string{"net/http.Header.Set", "sel.List.Push"}}
+		analysistest.Run(t, data, FindMethods(mustNewRun(t, config)), "sel")
+	})
+
+	t.Run("references", func(t *testing.T) {
+		config := Config{
+			Methods:   []string{"database/sql.DB.Exec", "refm.server.serve"},
+			Functions: []string{"os.ReadFile", "net/http.NotFound"},
+		}
+		analysistest.Run(t, data, FindMethods(mustNewRun(t, config)), "refm")
+		analysistest.Run(t, data, FindFunctions(mustNewRun(t, config)), "reff")
+	})
+
+	t.Run("unqualified functions", func(t *testing.T) {
+		config := Config{Functions: []string{"os.ReadFile", "unq.Exec", "unq.Map"}}
+		analysistest.Run(t, data, FindFunctions(mustNewRun(t, config)), "unq")
+	})
+
+	t.Run("argument predicates", func(t *testing.T) {
+		config := Config{
+			Methods: []string{"database/sql.DB.Query arg0:nonconst"},
+			Functions: []string{
+				"os/exec.Command arg0:nonconst",
+				"net/http.Get arg0:match=^http://",
+				"fmt.Fprintf arg0:type=net/http.ResponseWriter",
+			},
+		}
+		analysistest.Run(t, data, FindMethods(mustNewRun(t, config)), "predm")
+		analysistest.Run(t, data, FindFunctions(mustNewRun(t, config)), "predf")
+	})
+
+	t.Run("interfaces", func(t *testing.T) {
+		config := Config{Methods: []string{"io.Writer.Write"}, MatchInterfaces: true}
+		analysistest.Run(t, data, FindMethods(mustNewRun(t, config)), "iface")
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		config := Config{Fields: []string{"net/http.Request.Header", "crypto/tls.Config.(InsecureSkipVerify|ServerName|MinVersion)", "fld.endpoint.host"}}
+		analysistest.Run(t, data, FindFields(mustNewRun(t, config)), "fld")
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		config := Config{Tags: []string{"json", "db", "yaml"}}
+		analysistest.Run(t, data, FindTags(mustNewRun(t, config)), "tags")
+	})
+
 	t.Run("packages", func(t *testing.T) {
 		config := Config{Packages: []string{"path/filepath", "encoding/hex"}}
 		analyser := FindPackages(mustNewRun(t, config))
 		analysistest.Run(t, data, analyser, "p")
 	})
+
+	t.Run("package references", func(t *testing.T) {
+		config := Config{Packages: []string{"net/http", "crypto/tls", "os"}}
+		analysistest.Run(t, data, FindPackages(mustNewRun(t, config)), "pref")
+	})
+
+	t.Run("imports", func(t *testing.T) {
+		config := Config{Imports: []string{"net/http", "embed", "strings", "crypto/tls", "os/exec"}}
+		analysistest.Run(t, data, FindImports(mustNewRun(t, config)), "imp")
+	})
 }
 
 func mustNewRun(t *testing.T, c Config) *Runner {
@@ -48,3 +130,174 @@ func mustNewRun(t *testing.T, c Config) *Runner {
 	t.Cleanup(func() { r.Close() })
 	return r
 }
+
+func TestRunSeveralAnalysers(t *testing.T) {
+	config := Config{
+		Strings:   true,
+		Methods:   []string{"net/http.Header.Set"},
+		Functions: []string{"os.ReadFile"},
+		Packages:  []string{"encoding/hex"},
+	}
+	r, err := NewAnalysis(config, config.Analysers()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go r.Run([]string{"./testdata/src/http", "./testdata/src/o", "./testdata/src/p"})
+	report := BuildReport(r)
+
+	for category, exp := range map[string]struct{ got, want int }{
+		"strings":   {len(report.Strings), 10},
+		"methods":   {len(report.Methods), 1},
+		"functions": {len(report.Functions), 1},
+		"packages":  {len(report.Packages), 1},
+	} {
+		if exp.got != exp.want {
+			t.Errorf("%s: got %d items, want %d", category, exp.got, exp.want)
+		}
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	newRunner := func(t *testing.T) *Runner {
+		t.Helper()
+		config := Config{Functions: []string{"os.ReadFile"}}
+		r, err := NewAnalysis(config, config.Analysers()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	t.Run("report", func(t *testing.T) {
+		report, err := newRunner(t).RunContext(context.Background(), []string{"./testdata/src/o"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(report.Functions); got != 1 {
+			t.Fatalf("got %d functions, want 1", got)
+		}
+		if got := report.Functions[0].Function; got != "m" {
+			t.Fatalf("got enclosing function %q, want m", got)
+		}
+		if got := report.Functions[0].Symbol; got != "os.ReadFile" {
+			t.Fatalf("got symbol %q, want os.ReadFile", got)
+		}
+		if got := report.Functions[0].Kind; got != KindCall {
+			t.Fatalf("got kind %q, want %s", got, KindCall)
+		}
+	})
+
+	t.Run("imports", func(t *testing.T) {
+		config := Config{Imports: []string{"crypto/tls"}}
+		r, err := NewAnalysis(config, config.Analysers()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := r.RunContext(context.Background(), []string{"./testdata/src/imp"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(report.Imports); got != 1 {
+			t.Fatalf("got %d imports, want 1", got)
+		}
+		if got := report.Imports[0].Kind; got != ImportTransitive {
+			t.Fatalf("got kind %q, want %s", got, ImportTransitive)
+		}
+		if exp := "imp -> net/http -> crypto/tls"; !strings.HasSuffix(report.Imports[0].Value, exp) {
+			t.Fatalf("got %q, want chain %q", report.Imports[0].Value, exp)
+		}
+	})
+
+	t.Run("call graph", func(t *testing.T) {
+		config := Config{Functions: []string{"os/exec.Command"}, CallGraph: CallGraphCHA}
+		r, err := NewAnalysis(config, config.Analysers()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := r.RunContext(context.Background(), []string{"./testdata/src/reach/..."})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, i := range report.Reachability {
+			got = append(got, i.Package+" "+i.Function)
+		}
+		exp := []string{
+			"github.com/simcap/coi/testdata/src/reach/cmd main",
+			"github.com/simcap/coi/testdata/src/reach init",
+			"github.com/simcap/coi/testdata/src/reach Run",
+			"github.com/simcap/coi/testdata/src/reach Tool.Start",
+		}
+		if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+			t.Fatalf("got entry points %q, want %q", got, exp)
+		}
+		path := "github.com/simcap/coi/testdata/src/reach/cmd.main -> github.com/simcap/coi/testdata/src/reach.Run -> github.com/simcap/coi/testdata/src/reach.run -> os/exec.Command"
+		if v := report.Reachability[0].Value; !strings.HasSuffix(v, path) {
+			t.Fatalf("got %q, want path %q", v, path)
+		}
+	})
+
+	t.Run("call graph cancelled", func(t *testing.T) {
+		config := Config{Functions: []string{"os/exec.Command"}, CallGraph: CallGraphCHA}
+		r, err := NewAnalysis(config, config.Analysers()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan error)
+		go func() {
+			_, err := r.run(ctx, []string{"./testdata/src/reach/..."})
+			done <- err
+		}()
+		// Reachability items are reported once the analysis is done.
+		var reached int
+		for i := range r.ReportChan {
+			if i.Category == "reachability" {
+				reached++
+				cancel()
+			}
+		}
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+		if reached == 0 || reached > 2 {
+			t.Fatalf("got %d reachable entry points, want 1 or 2 before cancellation", reached)
+		}
+	})
+
+	t.Run("call graph with type errors", func(t *testing.T) {
+		config := Config{Functions: []string{"os/exec.Command"}, CallGraph: CallGraphCHA}
+		r, err := NewAnalysis(config, config.Analysers()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := r.RunContext(context.Background(), []string{"./testdata/src/reach", "./testdata/src/reachbad"})
+		var typeErr typeParseError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("got %v, want type error", err)
+		}
+		if got := len(report.Reachability); got != 3 {
+			t.Fatalf("got %d reachable entry points, want 3", got)
+		}
+	})
+
+	t.Run("load error", func(t *testing.T) {
+		report, err := newRunner(t).RunContext(context.Background(), []string{"./testdata/src/missing"})
+		if err == nil || !strings.Contains(err.Error(), "testdata/src/missing: directory not found") {
+			t.Fatalf("got %v, want missing directory error", err)
+		}
+		if report == nil {
+			t.Fatal("expected empty report")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := newRunner(t).RunContext(ctx, []string{"./testdata/src/o"})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	})
+}
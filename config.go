@@ -0,0 +1,229 @@
+package coi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigVersion is the only configuration file version understood by LoadConfig.
+const ConfigVersion = 1
+
+// Output holds the report settings of a configuration file.
+type Output struct {
+	Format string `yaml:"format"`
+	File   string `yaml:"file"`
+}
+
+// LoadConfig reads a YAML configuration file. Unknown keys and invalid
+// entries are reported with the file name and line they appear on.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return c, yamlError(path, err)
+	}
+	if len(root.Content) > 0 {
+		root = *root.Content[0]
+	}
+
+	var errs []error
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && err != io.EOF {
+		errs = append(errs, yamlError(path, err))
+	}
+	errorf := func(line int, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s:%d: %s", path, line, fmt.Sprintf(format, args...)))
+	}
+
+	if n := lookup(&root, "version"); n == nil {
+		errorf(root.Line, "missing version")
+	} else if c.Version != ConfigVersion {
+		errorf(n.Line, "unsupported version %d", c.Version)
+	}
+	for _, key := range []string{"methods", "functions"} {
+		if n := lookup(&root, key); n != nil {
+			for _, e := range n.Content {
+				if _, err := parseExpr(e.Value); err != nil {
+					errorf(e.Line, "invalid %s format: %s: %v", strings.TrimSuffix(key, "s"), e.Value, err)
+				}
+			}
+		}
+	}
+	for _, key := range []string{"include", "exclude"} {
+		if n := lookup(&root, key); n != nil {
+			for _, e := range n.Content {
+				if _, err := filepath.Match(strings.TrimSuffix(e.Value, "/..."), ""); err != nil {
+					errorf(e.Line, "invalid %s path: %s", key, e.Value)
+				}
+			}
+		}
+	}
+	if n := lookup(&root, "string_classes"); n != nil {
+		for _, e := range n.Content {
+			if !validClass(e.Value) {
+				errorf(e.Line, "unknown string class: %s", e.Value)
+			}
+		}
+	}
+	if n := lookup(&root, "fields"); n != nil {
+		for _, e := range n.Content {
+			if _, err := parseField(e.Value); err != nil {
+				errorf(e.Line, "invalid field format: %s: %v", e.Value, err)
+			}
+		}
+	}
+	if n := lookup(&root, "links"); n != nil {
+		if p := lookup(n, "provider"); p != nil {
+			if _, ok := LinkTemplates[p.Value]; !ok && p.Value != "custom" {
+				errorf(p.Line, "unknown link provider: %s", p.Value)
+			}
+		}
+	}
+	if n := lookup(&root, "call_graph"); n != nil && !validCallGraph(n.Value) {
+		errorf(n.Line, "unknown call graph algorithm: %s", n.Value)
+	}
+	if n := lookup(&root, "gate"); n != nil {
+		if max := lookup(n, "max"); max != nil {
+			for i := 0; i+1 < len(max.Content); i += 2 {
+				if k := max.Content[i]; !validCategory(k.Value) {
+					errorf(k.Line, "unknown category: %s", k.Value)
+				}
+			}
+		}
+		if failOn := lookup(n, "fail_on"); failOn != nil {
+			patterns := c.Patterns()
+			for _, e := range failOn.Content {
+				if !slices.Contains(patterns, e.Value) {
+					errorf(e.Line, "fail_on pattern is not configured: %s", e.Value)
+				}
+			}
+		}
+	}
+	if n := lookup(&root, "output"); n != nil {
+		if f := lookup(n, "format"); f != nil && !validFormat(f.Value) {
+			errorf(f.Line, "unknown output format: %s", f.Value)
+		}
+	}
+
+	return c, errors.Join(errs...)
+}
+
+// Analysers returns the analysers needed for the categories
+// enabled in the configuration.
+func (c Config) Analysers() []AnalyserFunc {
+	var all []AnalyserFunc
+	if c.Strings || len(c.StringClasses) > 0 {
+		all = append(all, FindStrings)
+	}
+	if c.Secrets {
+		all = append(all, FindSecrets)
+	}
+	if len(c.Methods) > 0 {
+		all = append(all, FindMethods)
+	}
+	if len(c.Functions) > 0 {
+		all = append(all, FindFunctions)
+	}
+	if len(c.Fields) > 0 {
+		all = append(all, FindFields)
+	}
+	if len(c.Tags) > 0 {
+		all = append(all, FindTags)
+	}
+	if len(c.Packages) > 0 {
+		all = append(all, FindPackages)
+	}
+	if len(c.Imports) > 0 {
+		all = append(all, FindImports)
+	}
+	return all
+}
+
+func validFormat(f string) bool {
+	switch f {
+	case "", "text", "html", "json", "sarif":
+		return true
+	}
+	return false
+}
+
+// lookup returns the value node of key in the mapping node n.
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+var yamlLineRegexp = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError rewrites yaml errors to the usual file:line: message form.
+func yamlError(path string, err error) error {
+	var msgs []string
+	if terr, ok := err.(*yaml.TypeError); ok {
+		msgs = terr.Errors
+	} else {
+		msgs = []string{err.Error()}
+	}
+	var errs []error
+	for _, msg := range msgs {
+		if m := yamlLineRegexp.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			errs = append(errs, fmt.Errorf("%s:%d: %s", path, line, m[2]))
+		} else {
+			errs = append(errs, fmt.Errorf("%s: %s", path, strings.TrimPrefix(msg, "yaml: ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Patterns returns the configured patterns findings are recorded with
+// in Item.Pattern, which gate fail_on entries refer to.
+func (c Config) Patterns() []string {
+	var patterns []string
+	for _, list := range [][]string{c.Methods, c.Functions, c.Fields, c.Tags, c.Packages, c.Imports} {
+		for _, p := range list {
+			// As recorded by Expr.String.
+			patterns = append(patterns, strings.Join(strings.Fields(p), " "))
+		}
+	}
+	return patterns
+}
+
+// matchPath reports whether the relative file path rel matches pattern.
+// A pattern ending in "/..." matches everything under that directory,
+// other patterns follow path.Match. As in .gitignore, patterns
+// without a slash are matched against the file name in any directory.
+func matchPath(pattern, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if dir, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return rel == dir || strings.HasPrefix(rel, dir+"/")
+	}
+	if !strings.Contains(pattern, "/") {
+		rel = path.Base(rel)
+	}
+	ok, _ := path.Match(pattern, rel)
+	return ok
+}
@@ -0,0 +1,186 @@
+package coi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), ".coi.yaml")
+		writeFile(t, path, content)
+		return path
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		path := write(t, `version: 1
+strings: true
+methods:
+  - net/http.Header.Set
+functions:
+  - os.ReadFile
+packages:
+  - encoding/hex
+include:
+  - internal/...
+exclude:
+  - "*_gen.go"
+output:
+  format: html
+  file: report.html
+`)
+		c, err := LoadConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := Config{
+			Version:   1,
+			Strings:   true,
+			Methods:   []string{"net/http.Header.Set"},
+			Functions: []string{"os.ReadFile"},
+			Packages:  []string{"encoding/hex"},
+			Include:   []string{"internal/..."},
+			Exclude:   []string{"*_gen.go"},
+			Output:    Output{Format: "html", File: "report.html"},
+		}
+		if !reflect.DeepEqual(c, exp) {
+			t.Fatalf("got %+v, want %+v", c, exp)
+		}
+		if got := len(c.Analysers()); got != 4 {
+			t.Fatalf("got %d analysers, want 4", got)
+		}
+	})
+
+	t.Run("string classes", func(t *testing.T) {
+		c, err := LoadConfig(write(t, "version: 1\nstring_classes:\n  - url\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(c.Analysers()); got != 1 {
+			t.Fatalf("got %d analysers, want strings only", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		path := write(t, `version: 1
+methods:
+  - net/http.Header.Set
+  - os
+unknown: true
+`)
+		_, err := LoadConfig(path)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if exp := path + ":5: field unknown not found"; !strings.Contains(err.Error(), exp) {
+			t.Fatalf("got %q, want it to contain %q", err, exp)
+		}
+
+		path = write(t, `version: 1
+functions:
+  - os.ReadFile
+  - os
+`)
+		_, err = LoadConfig(path)
+		if exp := path + ":4: invalid function format: os: missing dot separated name"; err == nil || err.Error() != exp {
+			t.Fatalf("got %v, want %q", err, exp)
+		}
+
+		path = write(t, `version: 1
+functions:
+  - os/exec.Command arg0:nonconst
+  - os/exec.Command arg0:bogus
+`)
+		_, err = LoadConfig(path)
+		if exp := path + `:4: invalid function format: os/exec.Command arg0:bogus: unknown condition in predicate "arg0:bogus"`; err == nil || err.Error() != exp {
+			t.Fatalf("got %v, want %q", err, exp)
+		}
+
+		path = write(t, `version: 1
+functions:
+  - os/exec.Command
+gate:
+  fail_on:
+    - os/exec.Command
+    - os/exec.Comand
+`)
+		_, err = LoadConfig(path)
+		if exp := path + ":7: fail_on pattern is not configured: os/exec.Comand"; err == nil || err.Error() != exp {
+			t.Fatalf("got %v, want %q", err, exp)
+		}
+
+		path = write(t, `version: 1
+fields:
+  - crypto/tls.Config.InsecureSkipVerify arg0:const
+`)
+		_, err = LoadConfig(path)
+		if exp := path + `:3: invalid field format: crypto/tls.Config.InsecureSkipVerify arg0:const: unexpected arguments predicates`; err == nil || err.Error() != exp {
+			t.Fatalf("got %v, want %q", err, exp)
+		}
+
+		path = write(t, "methods: []\n")
+		_, err = LoadConfig(path)
+		if exp := path + ":1: missing version"; err == nil || err.Error() != exp {
+			t.Fatalf("got %v, want %q", err, exp)
+		}
+	})
+}
+
+func TestMatchPath(t *testing.T) {
+	tcases := []struct {
+		pattern, path string
+		exp           bool
+	}{
+		{"internal/...", "internal/db/db.go", true},
+		{"internal/...", "internals/db.go", false},
+		{"*_gen.go", "types_gen.go", true},
+		{"*_gen.go", "sub/types_gen.go", true},
+		{"sub/*_gen.go", "sub/types_gen.go", true},
+		{"sub/*_gen.go", "other/sub/types_gen.go", false},
+		{"cmd/*/main.go", "cmd/coi/main.go", true},
+	}
+	for _, tc := range tcases {
+		if got := matchPath(tc.pattern, tc.path); got != tc.exp {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tc.pattern, tc.path, got, tc.exp)
+		}
+	}
+}
+
+func TestParseExprPointer(t *testing.T) {
+	e, err := parseExpr("*bytes.Buffer.Write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for typ, exp := range map[string]bool{"bytes.Buffer": true, "mybytes.Buffer": false, "gm.bytes.Buffer": false} {
+		if got := e.matchLeft(typ); got != exp {
+			t.Errorf("matchLeft(%q) = %v, want %v", typ, got, exp)
+		}
+	}
+	if e.String() != "*bytes.Buffer.Write" {
+		t.Errorf("got spec %q", e.String())
+	}
+
+	e, err = parseExpr("*Client.Do")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for typ, exp := range map[string]bool{"net/http.Client": true, "example.com/api.Client": true, "Client": true, "net/http.Request": false} {
+		if got := e.matchLeft(typ); got != exp {
+			t.Errorf("matchLeft(%q) = %v, want %v", typ, got, exp)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
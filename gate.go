@@ -0,0 +1,68 @@
+package coi
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Gate holds the conditions under which a report fails a build.
+type Gate struct {
+	// FailOnFindings fails on any item.
+	FailOnFindings bool `yaml:"fail_on_findings"`
+	// Max fails when a category has more items than its maximum.
+	Max map[string]int `yaml:"max"`
+	// FailOn fails when one of the given method, function or
+	// package patterns matched.
+	FailOn []string `yaml:"fail_on"`
+}
+
+// CategoryNames lists the item categories a gate maximum can apply to.
+var CategoryNames = []string{"strings", "secrets", "methods", "functions", "fields", "tags", "packages", "imports", "reachability"}
+
+// Enabled reports whether any condition is set.
+func (g Gate) Enabled() bool {
+	return g.FailOnFindings || len(g.Max) > 0 || len(g.FailOn) > 0
+}
+
+// Check returns a message for every condition the report violates.
+func (g Gate) Check(r *Report) []string {
+	var violations []string
+	items := r.Items()
+	if g.FailOnFindings && len(items) > 0 {
+		violations = append(violations, fmt.Sprintf("%d findings", len(items)))
+	}
+
+	counts := make(map[string]int)
+	matched := make(map[string]int)
+	for _, i := range items {
+		counts[i.Category]++
+		matched[i.Pattern]++
+	}
+
+	categories := make([]string, 0, len(g.Max))
+	for c := range g.Max {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	for _, c := range categories {
+		if counts[c] > g.Max[c] {
+			violations = append(violations, fmt.Sprintf("%d %s findings, maximum is %d", counts[c], c, g.Max[c]))
+		}
+	}
+
+	for _, p := range g.FailOn {
+		if n := matched[p]; n > 0 {
+			violations = append(violations, fmt.Sprintf("%s matched %d times", p, n))
+		}
+	}
+	return violations
+}
+
+func validCategory(c string) bool {
+	for _, known := range CategoryNames {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
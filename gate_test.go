@@ -0,0 +1,28 @@
+package coi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGate(t *testing.T) {
+	report := &Report{
+		Strings:   []Item{{Category: "strings"}, {Category: "strings"}},
+		Functions: []Item{{Category: "functions", Pattern: "os/exec.Command"}},
+	}
+
+	tcases := []struct {
+		gate Gate
+		exp  []string
+	}{
+		{Gate{}, nil},
+		{Gate{FailOnFindings: true}, []string{"3 findings"}},
+		{Gate{Max: map[string]int{"strings": 1, "functions": 1}}, []string{"2 strings findings, maximum is 1"}},
+		{Gate{FailOn: []string{"os/exec.Command", "unsafe"}}, []string{"os/exec.Command matched 1 times"}},
+	}
+	for _, tc := range tcases {
+		if got := tc.gate.Check(report); !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("%+v: got %q, want %q", tc.gate, got, tc.exp)
+		}
+	}
+}
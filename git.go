@@ -0,0 +1,219 @@
+package coi
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// gitRepo is a local git repository read directly from its .git directory.
+type gitRepo struct {
+	root      string // work tree root
+	gitDir    string // .git directory, per worktree
+	commonDir string // directory holding refs and config
+}
+
+// findGitRepo looks for the git repository containing dir.
+func findGitRepo(dir string) (*gitRepo, error) {
+	for d := dir; ; {
+		dotgit := filepath.Join(d, ".git")
+		if fi, err := os.Stat(dotgit); err == nil {
+			repo := &gitRepo{root: d, gitDir: dotgit, commonDir: dotgit}
+			if !fi.IsDir() {
+				// Worktrees and submodules use a file pointing to the git directory.
+				data, err := os.ReadFile(dotgit)
+				if err != nil {
+					return nil, err
+				}
+				gitdir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+				if !ok {
+					return nil, fmt.Errorf("invalid .git file: %s", dotgit)
+				}
+				if !filepath.IsAbs(gitdir) {
+					gitdir = filepath.Join(d, gitdir)
+				}
+				repo.gitDir, repo.commonDir = gitdir, gitdir
+			}
+			if data, err := os.ReadFile(filepath.Join(repo.gitDir, "commondir")); err == nil {
+				common := strings.TrimSpace(string(data))
+				if !filepath.IsAbs(common) {
+					common = filepath.Join(repo.gitDir, common)
+				}
+				repo.commonDir = common
+			}
+			return repo, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return nil, fmt.Errorf("no git repository found from %s", dir)
+		}
+		d = parent
+	}
+}
+
+// head returns the commit SHA currently checked out.
+func (g *gitRepo) head() (string, error) {
+	data, err := os.ReadFile(filepath.Join(g.gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	head := strings.TrimSpace(string(data))
+	ref, ok := strings.CutPrefix(head, "ref: ")
+	if !ok {
+		return head, nil
+	}
+	return g.resolve(ref)
+}
+
+// resolve returns the commit SHA of a full reference name such as refs/heads/main.
+func (g *gitRepo) resolve(ref string) (string, error) {
+	for _, dir := range []string{g.gitDir, g.commonDir} {
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(g.commonDir, "packed-refs"))
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", ref, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if sha, name, ok := strings.Cut(scanner.Text(), " "); ok && name == ref {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("cannot resolve %s", ref)
+}
+
+// remoteURL returns the URL of the given remote from the repository config.
+func (g *gitRepo) remoteURL(remote string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(g.commonDir, "config"))
+	if err != nil {
+		return "", err
+	}
+	section := fmt.Sprintf(`[remote "%s"]`, remote)
+	var inSection bool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == section
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && inSection && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", fmt.Errorf("no url for remote %s", remote)
+}
+
+// webURL turns a git remote URL, including scp-like SSH forms
+// such as git@github.com:org/repo.git, into a browsable https URL.
+func webURL(remote string) (string, error) {
+	if !strings.Contains(remote, "://") {
+		userHost, path, ok := strings.Cut(remote, ":")
+		if !ok {
+			return "", fmt.Errorf("unsupported remote url: %s", remote)
+		}
+		remote = "ssh://" + userHost + "/" + path
+	}
+	u, err := url.Parse(remote)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	host := u.Host
+	if u.Scheme != "http" && u.Scheme != "https" {
+		// The port of an SSH remote is not the one of the web server.
+		host = u.Hostname()
+	}
+	return "https://" + host + "/" + path, nil
+}
+
+// ChangedLines maps file paths, relative to the working directory,
+// to the lines added or changed in them.
+type ChangedLines map[string]map[int]bool
+
+// Contains reports whether the line of the file was changed.
+func (c ChangedLines) Contains(path string, line int) bool {
+	return c[filepath.ToSlash(path)][line]
+}
+
+// ChangesSince returns the lines of dir changed in the working tree
+// in tracked files since ref forked from HEAD, as a pull request review
+// would see them.
+func ChangesSince(dir, ref string) (ChangedLines, error) {
+	base, err := gitCommand(dir, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := gitCommand(dir, "-c", "core.quotepath=off", "diff", "--unified=0", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "--relative", strings.TrimSpace(base), "--")
+	if err != nil {
+		return nil, err
+	}
+	return parseDiff(diff)
+}
+
+func gitCommand(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+var hunkRegexp = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parseDiff collects the added lines of a unified diff with no context.
+func parseDiff(diff string) (ChangedLines, error) {
+	changed := make(ChangedLines)
+	var lines map[int]bool
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			lines = nil
+			// Paths with spaces are followed by a tab, paths with quotes,
+			// backslashes or control characters are C-quoted.
+			path := strings.TrimSuffix(line[len("+++ "):], "\t")
+			if strings.HasPrefix(path, `"`) {
+				unquoted, err := strconv.Unquote(path)
+				if err != nil {
+					return nil, fmt.Errorf("invalid file header: %s", line)
+				}
+				path = unquoted
+			}
+			if path, ok := strings.CutPrefix(path, "b/"); ok {
+				lines = make(map[int]bool)
+				changed[path] = lines
+			}
+		case strings.HasPrefix(line, "@@ "):
+			m := hunkRegexp.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("invalid hunk header: %s", line)
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			for i := start; i < start+count && lines != nil; i++ {
+				lines[i] = true
+			}
+		}
+	}
+	return changed, scanner.Err()
+}
@@ -0,0 +1,60 @@
+package coi
+
+import (
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 3f1b2a1..9c2d4e5 100644
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ import "os"
++var a = "a"
++var b = "b"
+@@ -10 +12 @@ func main() {
+-	os.ReadFile("x")
++	os.ReadFile("y")
+@@ -20,3 +22,0 @@ func main() {
+-	one
+-	two
+-	three
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+-
+diff --git a/my dir/my file.go b/my dir/my file.go
+index 3f1b2a1..9c2d4e5 100644
+--- a/my dir/my file.go	
++++ b/my dir/my file.go	
+@@ -1,0 +2 @@ package main
++var c = "c"
+diff --git "a/quo\"te.go" "b/quo\"te.go"
+index 3f1b2a1..9c2d4e5 100644
+--- "a/quo\"te.go"
++++ "b/quo\"te.go"
+@@ -1,0 +3 @@ package main
++var d = "d"
+`
+	changed, err := parseDiff(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for line, exp := range map[int]bool{3: false, 4: true, 5: true, 6: false, 12: true, 22: false} {
+		if got := changed.Contains("main.go", line); got != exp {
+			t.Errorf("line %d: got %v, want %v", line, got, exp)
+		}
+	}
+	if _, ok := changed["old.go"]; ok {
+		t.Error("deleted file should not have changed lines")
+	}
+	if !changed.Contains("my dir/my file.go", 2) {
+		t.Error("path with spaces should have changed lines")
+	}
+	if !changed.Contains(`quo"te.go`, 3) {
+		t.Error("quoted path should have changed lines")
+	}
+}
@@ -2,6 +2,8 @@ module github.com/simcap/coi
 
 go 1.21.0
 
-require golang.org/x/tools v0.16.0
-
-require golang.org/x/mod v0.14.0 // indirect
+require (
+	golang.org/x/mod v0.14.0
+	golang.org/x/tools v0.16.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,178 @@
+package coi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// Kinds of imports recorded in Item.Kind.
+const (
+	ImportDirect     = "direct"
+	ImportBlank      = "blank"
+	ImportDot        = "dot"
+	ImportTransitive = "transitive"
+)
+
+func FindImports(r *Runner) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: "imports",
+		Doc:  "Collect direct and transitive imports of given packages",
+		Run:  importsValues(r),
+	}
+}
+
+func importsValues(r *Runner) func(*analysis.Pass) (interface{}, error) {
+	return func(pass *analysis.Pass) (interface{}, error) {
+		graph := r.importGraph
+		if graph == nil {
+			// Not run by Runner.Run, such as in analysistest.
+			graph = typesImportGraph(pass.Pkg)
+		}
+
+		from := pass.Pkg.Path()
+		for _, target := range r.imports {
+			if target == from {
+				continue
+			}
+			var direct bool
+			for _, f := range pass.Files {
+				for _, spec := range f.Imports {
+					if importPath(spec) != target {
+						continue
+					}
+					direct = true
+					kind := ImportDirect
+					if spec.Name != nil {
+						switch spec.Name.Name {
+						case "_":
+							kind = ImportBlank
+						case ".":
+							kind = ImportDot
+						}
+					}
+					r.reportImport(pass, spec, target, kind, []string{from, target})
+				}
+			}
+			if direct {
+				continue
+			}
+			if chain := importChain(graph, from, target); chain != nil {
+				// Located at the import leading to the target.
+				r.reportImport(pass, importSpec(pass.Files, chain[1]), target, ImportTransitive, chain)
+			}
+		}
+
+		return nil, nil
+	}
+}
+
+func (r *Runner) reportImport(pass *analysis.Pass, spec *ast.ImportSpec, target, kind string, chain []string) {
+	pos := pass.Files[0].Package
+	if spec != nil {
+		pos = spec.Pos()
+	}
+	msg := fmt.Sprintf("%s import of %s: %s", kind, target, strings.Join(chain, " -> "))
+	pass.Report(analysis.Diagnostic{
+		Pos:      pos,
+		Category: "imports",
+		Message:  msg,
+	})
+	r.report(pass, nil, Item{Category: "imports", Pattern: target, Symbol: target, Kind: kind, Value: msg, Position: pass.Fset.Position(pos)})
+}
+
+func importPath(spec *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return path
+}
+
+// importSpec returns the first import of path in files, if any.
+func importSpec(files []*ast.File, path string) *ast.ImportSpec {
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			if importPath(spec) == path {
+				return spec
+			}
+		}
+	}
+	return nil
+}
+
+// importGraph maps the path of every loaded package, dependencies
+// included, to the sorted paths of the packages it imports.
+func importGraph(initial []*packages.Package) map[string][]string {
+	imports := make(map[string]map[string]bool)
+	packages.Visit(initial, nil, func(p *packages.Package) {
+		if imports[p.PkgPath] == nil {
+			imports[p.PkgPath] = make(map[string]bool)
+		}
+		for _, imp := range p.Imports {
+			imports[p.PkgPath][imp.PkgPath] = true
+		}
+	})
+	return sortedGraph(imports)
+}
+
+// typesImportGraph is like importGraph for a type checked package.
+// Packages imported from export data may not list all their imports.
+func typesImportGraph(pkg *types.Package) map[string][]string {
+	imports := make(map[string]map[string]bool)
+	var visit func(p *types.Package)
+	visit = func(p *types.Package) {
+		if imports[p.Path()] != nil {
+			return
+		}
+		imports[p.Path()] = make(map[string]bool)
+		for _, imp := range p.Imports() {
+			imports[p.Path()][imp.Path()] = true
+			visit(imp)
+		}
+	}
+	visit(pkg)
+	return sortedGraph(imports)
+}
+
+func sortedGraph(imports map[string]map[string]bool) map[string][]string {
+	graph := make(map[string][]string, len(imports))
+	for p, set := range imports {
+		paths := make([]string, 0, len(set))
+		for path := range set {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		graph[p] = paths
+	}
+	return graph
+}
+
+// importChain returns the shortest chain of imports from one package
+// to the target, both included, or nil when it does not depend on it.
+// Imports are explored in order so that the chain is stable.
+func importChain(graph map[string][]string, from, target string) []string {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p == target {
+			var chain []string
+			for ; p != ""; p = prev[p] {
+				chain = append([]string{p}, chain...)
+			}
+			return chain
+		}
+		for _, imp := range graph[p] {
+			if _, seen := prev[imp]; !seen {
+				prev[imp] = p
+				queue = append(queue, imp)
+			}
+		}
+	}
+	return nil
+}
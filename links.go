@@ -0,0 +1,136 @@
+package coi
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Links configures how source links are built for each item.
+//
+// Template placeholders are {repo} for the repository web URL, {ref}
+// for the branch, tag or commit, {path} for the file path relative to
+// the repository root, {file} for the absolute file path and {line}
+// and {column} for the position.
+type Links struct {
+	Provider   string `yaml:"provider"`
+	Template   string `yaml:"template"`
+	Repository string `yaml:"repository"`
+	Ref        string `yaml:"ref"`
+}
+
+// LinkTemplates holds the URL templates of the built-in link providers.
+var LinkTemplates = map[string]string{
+	"github":    "{repo}/blob/{ref}/{path}#L{line}",
+	"gitlab":    "{repo}/-/blob/{ref}/{path}#L{line}",
+	"bitbucket": "{repo}/src/{ref}/{path}#lines-{line}",
+	"gitea":     "{repo}/src/commit/{ref}/{path}#L{line}",
+	"vscode":    "vscode://file/{file}:{line}:{column}",
+}
+
+// linker builds the source link of items.
+type linker struct {
+	template string
+	repo     string
+	ref      string
+	root     string
+}
+
+// newLinker resolves the link settings. The repository URL and its
+// provider default to the origin remote of the local git repository,
+// the ref defaults to the current commit.
+func newLinker(c Links, module, workingDir string) (*linker, error) {
+	l := &linker{template: c.Template, repo: strings.TrimSuffix(c.Repository, "/"), ref: c.Ref, root: workingDir}
+
+	git, gitErr := findGitRepo(workingDir)
+	if gitErr == nil {
+		l.root = git.root
+		if l.repo == "" {
+			if remote, err := git.remoteURL("origin"); err == nil {
+				l.repo, _ = webURL(remote)
+			}
+		}
+		if l.ref == "" {
+			l.ref, _ = git.head()
+		}
+	}
+	if l.repo == "" {
+		l.repo = "https://" + module
+	}
+	if l.ref == "" {
+		l.ref = "main"
+	}
+
+	switch {
+	case c.Provider == "custom" || (c.Provider == "" && c.Template != ""):
+		if l.template == "" {
+			return l, fmt.Errorf("custom link provider requires a template")
+		}
+	case c.Provider == "":
+		l.template = LinkTemplates[detectProvider(l.repo)]
+	default:
+		t, ok := LinkTemplates[c.Provider]
+		if !ok {
+			return l, fmt.Errorf("unknown link provider: %s", c.Provider)
+		}
+		l.template = t
+	}
+	return l, nil
+}
+
+// detectProvider guesses the forge from the repository URL host.
+func detectProvider(repo string) string {
+	u, err := url.Parse(repo)
+	if err != nil {
+		return "github"
+	}
+	host := u.Hostname()
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return "gitlab"
+	case strings.Contains(host, "bitbucket"):
+		return "bitbucket"
+	case strings.Contains(host, "gitea"), host == "codeberg.org":
+		return "gitea"
+	}
+	return "github"
+}
+
+// escapePath escapes each segment of a slash separated path so that
+// characters such as #, ? or spaces do not break links.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// htmlLink returns the link to render in the HTML report. Links are
+// left to html/template sanitisation, except for the editor scheme of
+// the vscode provider that it would filter out.
+func htmlLink(link string) interface{} {
+	if u, err := url.Parse(link); err == nil && u.Scheme == "vscode" {
+		return template.URL(u.String())
+	}
+	return link
+}
+
+// link returns the source link of the item.
+func (l *linker) link(i Item) string {
+	path, err := filepath.Rel(l.root, i.Position.Filename)
+	if err != nil {
+		path = i.RelativeFilepath
+	}
+	return strings.NewReplacer(
+		"{repo}", l.repo,
+		"{ref}", l.ref,
+		"{path}", escapePath(filepath.ToSlash(path)),
+		"{file}", escapePath(filepath.ToSlash(i.Position.Filename)),
+		"{line}", strconv.Itoa(i.Position.Line),
+		"{column}", strconv.Itoa(i.Position.Column),
+	).Replace(l.template)
+}
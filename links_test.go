@@ -0,0 +1,72 @@
+package coi
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".git/HEAD":        "ref: refs/heads/develop\n",
+		".git/packed-refs": "# pack-refs with: peeled fully-peeled sorted\n0123456789abcdef0123456789abcdef01234567 refs/heads/develop\n",
+		".git/config":      "[core]\n\tbare = false\n[remote \"origin\"]\n\turl = git@gitlab.com:acme/app.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n",
+	}
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	item := Item{Position: token.Position{Filename: filepath.Join(dir, "cmd", "main.go"), Line: 7, Column: 2}}
+
+	tcases := []struct {
+		links Links
+		exp   string
+	}{
+		{Links{}, "https://gitlab.com/acme/app/-/blob/0123456789abcdef0123456789abcdef01234567/cmd/main.go#L7"},
+		{Links{Provider: "github", Repository: "https://github.com/acme/app", Ref: "main"}, "https://github.com/acme/app/blob/main/cmd/main.go#L7"},
+		{Links{Provider: "bitbucket", Ref: "v1.0.0"}, "https://gitlab.com/acme/app/src/v1.0.0/cmd/main.go#lines-7"},
+		{Links{Provider: "gitea", Repository: "https://codeberg.org/acme/app/"}, "https://codeberg.org/acme/app/src/commit/0123456789abcdef0123456789abcdef01234567/cmd/main.go#L7"},
+		{Links{Provider: "vscode"}, "vscode://file/" + filepath.ToSlash(item.Position.Filename) + ":7:2"},
+		{Links{Template: "https://git.acme.io/{ref}/{path}?line={line}", Ref: "main"}, "https://git.acme.io/main/cmd/main.go?line=7"},
+	}
+	for _, tc := range tcases {
+		l, err := newLinker(tc.links, "acme.io/app", filepath.Join(dir, "cmd"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := l.link(item); got != tc.exp {
+			t.Errorf("%+v: got %s, want %s", tc.links, got, tc.exp)
+		}
+	}
+
+	l, err := newLinker(Links{Provider: "github", Repository: "https://github.com/acme/app", Ref: "main"}, "", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Position.Filename = filepath.Join(dir, "docs", "a b#c?.go")
+	if got, exp := l.link(item), "https://github.com/acme/app/blob/main/docs/a%20b%23c%3F.go#L7"; got != exp {
+		t.Errorf("got %s, want %s", got, exp)
+	}
+
+	if _, err := newLinker(Links{Provider: "unknown"}, "", dir); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+}
+
+func TestWebURL(t *testing.T) {
+	for remote, exp := range map[string]string{
+		"git@github.com:simcap/coi.git":           "https://github.com/simcap/coi",
+		"ssh://git@git.acme.io:2222/team/app.git": "https://git.acme.io/team/app",
+		"https://user@bitbucket.org/team/app.git": "https://bitbucket.org/team/app",
+		"https://gitea.acme.io/team/app":          "https://gitea.acme.io/team/app",
+		"https://git.acme.io:8443/team/app.git":   "https://git.acme.io:8443/team/app",
+	} {
+		got, err := webURL(remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != exp {
+			t.Errorf("%s: got %s, want %s", remote, got, exp)
+		}
+	}
+}
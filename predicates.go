@@ -0,0 +1,107 @@
+package coi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// argPredicate is a condition on one argument of a call.
+type argPredicate struct {
+	index int
+	kind  string
+	re    *regexp.Regexp
+	typ   string
+}
+
+// parsePredicate parses an argument predicate, argN:COND where N is
+// the argument position from 0 and COND one of:
+//
+//	const        the argument is a compile-time constant
+//	nonconst     the argument is not a compile-time constant
+//	match=REGEX  the argument is a constant whose value matches REGEX
+//	type=TYPE    the argument type is TYPE, such as string or *os.File
+//
+// For instance "os/exec.Command arg0:nonconst" only matches commands
+// built from a variable name.
+func parsePredicate(s string) (argPredicate, error) {
+	var p argPredicate
+	arg, cond, ok := strings.Cut(s, ":")
+	index, found := strings.CutPrefix(arg, "arg")
+	if !ok || !found {
+		return p, fmt.Errorf("invalid argument predicate %q: want argN:condition", s)
+	}
+	var err error
+	if p.index, err = strconv.Atoi(index); err != nil || p.index < 0 {
+		return p, fmt.Errorf("invalid argument position in %q", s)
+	}
+
+	kind, value, _ := strings.Cut(cond, "=")
+	p.kind = kind
+	switch kind {
+	case "const", "nonconst":
+		if value != "" {
+			return p, fmt.Errorf("unexpected value in predicate %q", s)
+		}
+	case "match":
+		if p.re, err = regexp.Compile(value); err != nil {
+			return p, fmt.Errorf("invalid regular expression in %q: %w", s, err)
+		}
+	case "type":
+		if value == "" {
+			return p, fmt.Errorf("missing type in predicate %q", s)
+		}
+		p.typ = value
+	default:
+		return p, fmt.Errorf("unknown condition in predicate %q", s)
+	}
+	return p, nil
+}
+
+// eval reports whether the call arguments satisfy the predicate.
+func (p argPredicate) eval(info *types.Info, args []ast.Expr) bool {
+	if p.index >= len(args) {
+		return false
+	}
+	tv := info.Types[args[p.index]]
+	switch p.kind {
+	case "const":
+		return tv.Value != nil
+	case "nonconst":
+		return tv.Value == nil
+	case "match":
+		if tv.Value == nil {
+			return false
+		}
+		v := tv.Value.ExactString()
+		if tv.Value.Kind() == constant.String {
+			v = constant.StringVal(tv.Value)
+		}
+		return p.re.MatchString(v)
+	case "type":
+		return tv.Type != nil && types.TypeString(tv.Type, nil) == p.typ
+	}
+	return false
+}
+
+// matchArgs reports whether the call satisfies every argument
+// predicate of the expression. References, having no arguments,
+// only match expressions without predicates.
+func (e Expr) matchArgs(info *types.Info, call *ast.CallExpr, args []ast.Expr) bool {
+	if len(e.args) == 0 {
+		return true
+	}
+	if call == nil {
+		return false
+	}
+	for _, p := range e.args {
+		if !p.eval(info, args) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,10 +3,14 @@ package coi
 import (
 	"embed"
 	_ "embed"
+	"encoding/json"
 	"fmt"
+	"go/token"
 	"html/template"
 	"io"
 	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 )
 
@@ -14,26 +18,53 @@ import (
 var htmlDir embed.FS
 
 type Report struct {
-	Strings   []Item
-	Methods   []Item
-	Functions []Item
-	Packages  []Item
+	Module     string
+	WorkingDir string
+	Rules      []Rule
+	Strings    []Item
+	Secrets    []Item
+	Methods    []Item
+	Functions  []Item
+	Fields     []Item
+	Tags       []Item
+	Packages   []Item
+	Imports    []Item
+	// Reachability lists the entry points reaching functions
+	// and methods of interest in the call graph.
+	Reachability []Item
+	// Resolved lists the baseline entries not found anymore.
+	Resolved []BaselineEntry
 }
 
 func BuildReport(r *Runner) *Report {
-	report := new(Report)
+	report := &Report{Module: r.module, WorkingDir: r.workingDir, Rules: r.Rules()}
 	for item := range r.ReportChan {
 		item.RelativeFilepath = r.GetRelativeFilepath(item)
-		item.GithubLink = fmt.Sprintf("https://%s/blob/main/%s#L%d", r.module, item.RelativeFilepath, item.Position.Line)
+		if !r.keep(item.RelativeFilepath) {
+			continue
+		}
+		item.Module = r.module
+		item.Link = r.links.link(item)
+		item.GithubLink = item.Link
 		switch item.Category {
 		case "strings":
 			report.Strings = append(report.Strings, item)
+		case "secrets":
+			report.Secrets = append(report.Secrets, item)
 		case "methods":
 			report.Methods = append(report.Methods, item)
 		case "functions":
 			report.Functions = append(report.Functions, item)
+		case "fields":
+			report.Fields = append(report.Fields, item)
+		case "tags":
+			report.Tags = append(report.Tags, item)
 		case "packages":
 			report.Packages = append(report.Packages, item)
+		case "imports":
+			report.Imports = append(report.Imports, item)
+		case "reachability":
+			report.Reachability = append(report.Reachability, item)
 		}
 	}
 	sorting(report)
@@ -44,18 +75,162 @@ func (r *Report) ToText(w io.WriteCloser) {
 	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
 	printItems := func(items []Item) {
 		for _, i := range items {
-			fmt.Fprintf(tw, "%s\t%s\n", i.Position, i.Value)
+			value := i.Value
+			if i.Category == "strings" {
+				value = strconv.Quote(value)
+			}
+			fmt.Fprintf(tw, "%s\t%s%s\n", i.Position, value, i.details())
 		}
 	}
 	printItems(r.Strings)
+	printItems(r.Secrets)
 	printItems(r.Functions)
 	printItems(r.Methods)
+	printItems(r.Fields)
+	printItems(r.Tags)
 	printItems(r.Packages)
+	printItems(r.Imports)
+	printItems(r.Reachability)
+	for _, e := range r.Resolved {
+		fmt.Fprintf(tw, "%s:%d\t%s (resolved)\n", e.File, e.Line, e.Value)
+	}
 	tw.Flush()
 }
 
+// JSONSchemaVersion is the version of the document written by ToJSON.
+// It only changes when fields are renamed or removed, new fields can
+// be added within the same version. Version 2 records string values
+// unquoted.
+const JSONSchemaVersion = 2
+
+type jsonReport struct {
+	SchemaVersion int             `json:"schema_version"`
+	Module        string          `json:"module"`
+	Items         []jsonItem      `json:"items"`
+	Resolved      []BaselineEntry `json:"resolved,omitempty"`
+}
+
+type jsonItem struct {
+	Category     string   `json:"category"`
+	Value        string   `json:"value"`
+	File         string   `json:"file"`
+	Line         int      `json:"line"`
+	Column       int      `json:"column"`
+	RelativePath string   `json:"relative_path"`
+	Link         string   `json:"link"`
+	Module       string   `json:"module"`
+	Analyser     string   `json:"analyser"`
+	Package      string   `json:"package"`
+	Pattern      string   `json:"pattern,omitempty"`
+	Symbol       string   `json:"symbol,omitempty"`
+	Kind         string   `json:"kind,omitempty"`
+	Match        string   `json:"match,omitempty"`
+	Object       string   `json:"object,omitempty"`
+	Constant     string   `json:"constant,omitempty"`
+	Hash         string   `json:"hash,omitempty"`
+	Class        string   `json:"class,omitempty"`
+	Role         string   `json:"role,omitempty"`
+	TagKey       string   `json:"tag_key,omitempty"`
+	TagName      string   `json:"tag_name,omitempty"`
+	TagOptions   []string `json:"tag_options,omitempty"`
+	Inconsistent []string `json:"inconsistent_with,omitempty"`
+	Parts        []string `json:"parts,omitempty"`
+	Function     string   `json:"function,omitempty"`
+}
+
+// ToJSON writes all items of the report as a JSON document
+// following the JSONSchemaVersion schema.
+func (r *Report) ToJSON(w io.Writer) error {
+	out := jsonReport{SchemaVersion: JSONSchemaVersion, Module: r.Module, Items: []jsonItem{}, Resolved: r.Resolved}
+	for _, i := range r.Items() {
+		out.Items = append(out.Items, jsonItem{
+			Category:     i.Category,
+			Value:        i.Value,
+			File:         i.Position.Filename,
+			Line:         i.Position.Line,
+			Column:       i.Position.Column,
+			RelativePath: i.RelativeFilepath,
+			Link:         i.Link,
+			Module:       i.Module,
+			Analyser:     i.Analyser,
+			Package:      i.Package,
+			Pattern:      i.Pattern,
+			Symbol:       i.Symbol,
+			Kind:         i.Kind,
+			Match:        i.Match,
+			Object:       i.Object,
+			Constant:     i.Constant,
+			Hash:         i.Hash,
+			Class:        i.Class,
+			Role:         i.Role,
+			TagKey:       i.TagKey,
+			TagName:      i.TagName,
+			TagOptions:   i.TagOptions,
+			Inconsistent: i.Inconsistent,
+			Parts:        i.Parts,
+			Function:     i.Function,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// Items returns the items of every category.
+func (r *Report) Items() []Item {
+	var all []Item
+	for _, items := range [][]Item{r.Strings, r.Secrets, r.Functions, r.Methods, r.Fields, r.Tags, r.Packages, r.Imports, r.Reachability} {
+		all = append(all, items...)
+	}
+	return all
+}
+
+// Filter keeps only the items for which keep returns true.
+func (r *Report) Filter(keep func(Item) bool) {
+	filter := func(items []Item) []Item {
+		var kept []Item
+		for _, i := range items {
+			if keep(i) {
+				kept = append(kept, i)
+			}
+		}
+		return kept
+	}
+	r.Strings = filter(r.Strings)
+	r.Secrets = filter(r.Secrets)
+	r.Methods = filter(r.Methods)
+	r.Functions = filter(r.Functions)
+	r.Fields = filter(r.Fields)
+	r.Tags = filter(r.Tags)
+	r.Packages = filter(r.Packages)
+	r.Imports = filter(r.Imports)
+	r.Reachability = filter(r.Reachability)
+}
+
+// Category groups the items of one category of the report.
+type Category struct {
+	Name  string
+	Items []Item
+}
+
+// Categories returns every category of the report, even empty ones.
+func (r *Report) Categories() []Category {
+	return []Category{
+		{"Strings", r.Strings},
+		{"Secrets", r.Secrets},
+		{"Methods", r.Methods},
+		{"Functions", r.Functions},
+		{"Fields", r.Fields},
+		{"Tags", r.Tags},
+		{"Packages", r.Packages},
+		{"Imports", r.Imports},
+		{"Reachability", r.Reachability},
+	}
+}
+
 func (r *Report) ToHTML(w io.WriteCloser) {
-	tmpl, err := template.New("report.html").ParseFS(htmlDir, "html/*")
+	funcs := template.FuncMap{"link": htmlLink, "join": strings.Join}
+	tmpl, err := template.New("report.html").Funcs(funcs).ParseFS(htmlDir, "html/*")
 	if err != nil {
 		panic(err)
 	}
@@ -67,6 +242,24 @@ func (r *Report) ToHTML(w io.WriteCloser) {
 
 func sorting(r *Report) {
 	sort.Slice(r.Strings, func(i, j int) bool {
-		return r.Strings[i].Value < r.Strings[j].Value
+		if r.Strings[i].Value != r.Strings[j].Value {
+			return r.Strings[i].Value < r.Strings[j].Value
+		}
+		return lessPosition(r.Strings[i].Position, r.Strings[j].Position)
 	})
+	for _, items := range [][]Item{r.Secrets, r.Methods, r.Functions, r.Fields, r.Tags, r.Packages, r.Imports, r.Reachability} {
+		sort.Slice(items, func(i, j int) bool {
+			return lessPosition(items[i].Position, items[j].Position)
+		})
+	}
+}
+
+func lessPosition(a, b token.Position) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
 }
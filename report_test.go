@@ -0,0 +1,150 @@
+package coi
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestReportToJSON(t *testing.T) {
+	report := &Report{
+		Module: "example.com/m",
+		Functions: []Item{{
+			Category:         "functions",
+			Position:         token.Position{Filename: "/src/m/main.go", Line: 12, Column: 3},
+			RelativeFilepath: "main.go",
+			Link:             "https://example.com/m/blob/main/main.go#L12",
+			Module:           "example.com/m",
+			Analyser:         "functions",
+			Value:            `os.ReadFile("any")`,
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := report.ToJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if v := got["schema_version"]; v != float64(JSONSchemaVersion) {
+		t.Fatalf("got schema version %v", v)
+	}
+	items := got["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	exp := map[string]interface{}{
+		"category":      "functions",
+		"value":         `os.ReadFile("any")`,
+		"file":          "/src/m/main.go",
+		"line":          float64(12),
+		"column":        float64(3),
+		"relative_path": "main.go",
+		"link":          "https://example.com/m/blob/main/main.go#L12",
+		"module":        "example.com/m",
+		"analyser":      "functions",
+	}
+	item := items[0].(map[string]interface{})
+	for k, v := range exp {
+		if item[k] != v {
+			t.Errorf("%s: got %v, want %v", k, item[k], v)
+		}
+	}
+}
+
+func TestReportToSARIF(t *testing.T) {
+	item := Item{
+		Category:         "functions",
+		Pattern:          "os.ReadFile",
+		Position:         token.Position{Filename: "/src/m/main.go", Line: 12, Column: 3},
+		RelativeFilepath: "main.go",
+		Value:            `os.ReadFile("any")`,
+		Role:             "call",
+	}
+	report := &Report{
+		WorkingDir: "/src/m",
+		Rules:      []Rule{{Category: "functions", Pattern: "os.ReadFile"}, {Category: "functions", Pattern: "os.WriteFile"}},
+		Functions:  []Item{item, item},
+	}
+
+	var buf bytes.Buffer
+	if err := report.ToSARIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log %+v", log)
+	}
+	run := log.Runs[0]
+	if got := len(run.Tool.Driver.Rules); got != 2 {
+		t.Fatalf("got %d rules, want 2", got)
+	}
+	if got := run.OriginalURIBaseIDs[sarifSrcRoot].URI; got != "file:///src/m/" {
+		t.Fatalf("got base uri %q", got)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(run.Results))
+	}
+	res := run.Results[0]
+	if res.RuleID != "functions:os.ReadFile" || res.RuleIndex != 0 {
+		t.Fatalf("got rule %s at %d", res.RuleID, res.RuleIndex)
+	}
+	if res.Message.Text != `os.ReadFile("any") [call]` {
+		t.Fatalf("got message %q", res.Message.Text)
+	}
+	loc := res.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "main.go" || loc.ArtifactLocation.URIBaseID != sarifSrcRoot || loc.Region.StartLine != 12 {
+		t.Fatalf("unexpected location %+v", loc)
+	}
+	first, second := res.PartialFingerprints[fingerprintKey], run.Results[1].PartialFingerprints[fingerprintKey]
+	if first == "" || first == second {
+		t.Fatalf("expected distinct fingerprints, got %q and %q", first, second)
+	}
+}
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestReportToHTML(t *testing.T) {
+	report := &Report{
+		Strings:   []Item{{Category: "strings", Value: "literal_1", Class: ClassEnv, Role: "arg 0 of os.Getenv"}},
+		Methods:   []Item{{Category: "methods", Value: "net/http.Header.Set()", Match: MatchInterface, Link: "javascript:alert(1)"}},
+		Functions: []Item{{Category: "functions", Value: "os.ReadFile()", Package: "example.com/m/o", Link: "vscode://file/o/o.go:3:1"}},
+		Packages:  []Item{{Category: "packages", Value: "encoding/hex.DecodeString()"}},
+	}
+
+	buf := nopCloser{new(bytes.Buffer)}
+	report.ToHTML(buf)
+	out := buf.String()
+
+	for _, exp := range []string{
+		"Strings <span class=\"count\">(1)</span>",
+		"Methods <span class=\"count\">(1)</span>",
+		"Functions <span class=\"count\">(1)</span>",
+		"Packages <span class=\"count\">(1)</span>",
+		"literal_1",
+		`<span class="count">(env)</span>`,
+		`<span class="count">[arg 0 of os.Getenv]</span>`,
+		"net/http.Header.Set()",
+		`<span class="count">(via interface)</span>`,
+		"os.ReadFile()",
+		"encoding/hex.DecodeString()",
+		`data-package="example.com/m/o"`,
+		`href="vscode://file/o/o.go:3:1"`,
+		`href="#ZgotmplZ"`,
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected HTML report to contain %q", exp)
+		}
+	}
+}
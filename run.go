@@ -12,6 +12,7 @@ package coi
 // driver that is conventionally provided for convenience along with
 // each analysis package, and the test driver.
 import (
+	"context"
 	"errors"
 	"fmt"
 	"go/token"
@@ -40,19 +41,102 @@ var IncludeTests = false
 // singlechecker and the multi-analysis commands.
 // It returns the appropriate exit code.
 func (r *Runner) Run(args []string) int {
-	initial, err := load(args, false)
-	if err != nil {
+	if _, err := r.run(context.Background(), args); err != nil {
 		if _, ok := err.(typeParseError); !ok {
 			// Fail when some of the errors are not
 			// related to parsing nor typing.
 			log.Fatal(err)
 		}
+		log.Println(err)
 	}
+	return 0
+}
+
+// RunContext loads the packages matching patterns, applies the
+// analysers to them and returns the report of collected items.
+// It never ends the process: load errors and the errors of each
+// analysis action are returned along with the partial report.
+// Analysis stops early when ctx is cancelled.
+//
+// As the report channel is closed at the end of the run, a Runner
+// can only be run once.
+func (r *Runner) RunContext(ctx context.Context, patterns []string) (*Report, error) {
+	done := make(chan *Report)
+	go func() { done <- BuildReport(r) }()
+
+	roots, err := r.run(ctx, patterns)
+	report := <-done
+
+	errs := []error{err}
+	for _, act := range failedActions(roots) {
+		errs = append(errs, &ActionError{Analyser: act.a.Name, Package: act.pkg.PkgPath, Err: act.err})
+	}
+	return report, errors.Join(errs...)
+}
+
+// run loads the packages and executes the analysis actions,
+// closing the report channel when done.
+func (r *Runner) run(ctx context.Context, patterns []string) ([]*action, error) {
 	defer r.Close()
 
+	// The call graph is built from the syntax of every dependency.
+	initial, err := load(ctx, patterns, r.callGraph != "")
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("%w: %v", ctxErr, err)
+		}
+		if _, ok := err.(typeParseError); !ok {
+			return nil, err
+		}
+	}
+
+	if len(r.imports) > 0 {
+		r.importGraph = importGraph(initial)
+	}
+
 	// Run the analysis.
-	analyze(initial, r.analysers)
-	return 0
+	roots := analyze(ctx, initial, r.analysers)
+	if r.callGraph != "" {
+		// Ill-typed packages are left out of the call graph.
+		if reachErr := r.reachability(ctx, initial); reachErr != nil {
+			err = errors.Join(err, reachErr)
+		}
+	}
+	return roots, err
+}
+
+// ActionError is the error of one analyser applied to one package.
+type ActionError struct {
+	Analyser string
+	Package  string
+	Err      error
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("%s@%s: %v", e.Analyser, e.Package, e.Err)
+}
+
+func (e *ActionError) Unwrap() error { return e.Err }
+
+// failedActions returns the actions, roots and dependencies,
+// that ended with an error.
+func failedActions(roots []*action) []*action {
+	var failed []*action
+	seen := make(map[*action]bool)
+	var visit func(actions []*action)
+	visit = func(actions []*action) {
+		for _, act := range actions {
+			if !seen[act] {
+				seen[act] = true
+				visit(act.deps)
+				if act.err != nil {
+					failed = append(failed, act)
+				}
+			}
+		}
+	}
+	visit(roots)
+	return failed
 }
 
 // typeParseError represents a package load error
@@ -63,15 +147,16 @@ type typeParseError struct {
 
 // load loads the initial packages. If all loading issues are related to
 // typing and parsing, the returned error is of type typeParseError.
-func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
+func load(ctx context.Context, patterns []string, allSyntax bool) ([]*packages.Package, error) {
 	mode := packages.LoadSyntax
 	if allSyntax {
 		mode = packages.LoadAllSyntax
 	}
 	mode |= packages.NeedModule
 	conf := packages.Config{
-		Mode:  mode,
-		Tests: IncludeTests,
+		Context: ctx,
+		Mode:    mode,
+		Tests:   IncludeTests,
 	}
 	initial, err := packages.Load(&conf, patterns...)
 	if err == nil {
@@ -84,35 +169,31 @@ func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 	return initial, err
 }
 
-// loadingError checks for issues during the loading of initial
-// packages. Returns nil if there are no issues. Returns error
-// of type typeParseError if all errors, including those in
-// dependencies, are related to typing or parsing. Otherwise,
-// a plain error is returned with an appropriate message.
+// loadingError collects the issues during the loading of initial
+// packages, dependencies included. Returns nil if there are no issues.
+// Returns error of type typeParseError if all errors are related to
+// typing or parsing. Otherwise, the errors are returned joined.
 func loadingError(initial []*packages.Package) error {
-	var err error
-	if n := packages.PrintErrors(initial); n > 1 {
-		err = fmt.Errorf("%d errors during loading", n)
-	} else if n == 1 {
-		err = errors.New("error during loading")
-	} else {
-		// no errors
-		return nil
-	}
+	var errs []error
 	all := true
 	packages.Visit(initial, nil, func(pkg *packages.Package) {
 		for _, err := range pkg.Errors {
+			errs = append(errs, err)
 			typeOrParse := err.Kind == packages.TypeError || err.Kind == packages.ParseError
 			all = all && typeOrParse
 		}
 	})
+	if len(errs) == 0 {
+		return nil
+	}
+	err := errors.Join(errs...)
 	if all {
 		return typeParseError{err}
 	}
 	return err
 }
 
-func analyze(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action {
+func analyze(ctx context.Context, pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action {
 	// Each graph node (action) is one unit of analysis.
 	// Edges express package-to-package (vertical) dependencies,
 	// and analysis-to-analysis (horizontal) dependencies.
@@ -164,82 +245,11 @@ func analyze(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action
 	}
 
 	// Execute the graph in parallel.
-	execAll(roots)
+	execAll(ctx, roots)
 
 	return roots
 }
 
-// printDiagnostics prints the diagnostics for the root packages in either
-// plain text or JSON format. JSON format also includes errors for any
-// dependencies.
-//
-// It returns the exitcode: in plain mode, 0 for success, 1 for analysis
-// errors, and 3 for diagnostics. We avoid 2 since the flag package uses
-// it. JSON mode always succeeds at printing errors and diagnostics in a
-// structured form to stdout.
-func printDiagnostics(roots []*action) (exitcode int) {
-	// Print the output.
-	//
-	// Print diagnostics only for root packages,
-	// but errors for all packages.
-	printed := make(map[*action]bool)
-	var print func(*action)
-	var visitAll func(actions []*action)
-	visitAll = func(actions []*action) {
-		for _, act := range actions {
-			if !printed[act] {
-				printed[act] = true
-				visitAll(act.deps)
-				print(act)
-			}
-		}
-	}
-
-	// plain text output
-
-	// De-duplicate diagnostics by position (not token.Pos) to
-	// avoid double-reporting in source files that belong to
-	// multiple packages, such as foo and foo.test.
-	type key struct {
-		pos token.Position
-		end token.Position
-		*analysis.Analyzer
-		message string
-	}
-	seen := make(map[key]bool)
-
-	print = func(act *action) {
-		if act.err != nil {
-			fmt.Fprintf(os.Stderr, "%s: %v\n", act.a.Name, act.err)
-			exitcode = 1 // analysis failed, at least partially
-			return
-		}
-		if act.isroot {
-			for _, diag := range act.diagnostics {
-				// We don't display a.Name/f.Category
-				// as most users don't care.
-
-				posn := act.pkg.Fset.Position(diag.Pos)
-				end := act.pkg.Fset.Position(diag.End)
-				k := key{posn, end, act.a, diag.Message}
-				if seen[k] {
-					continue // duplicate
-				}
-				seen[k] = true
-
-				PrintPlain(act.pkg.Fset, diag)
-			}
-		}
-	}
-	visitAll(roots)
-
-	if exitcode == 0 && len(seen) > 0 {
-		exitcode = 3 // successfully produced diagnostics
-	}
-
-	return exitcode
-}
-
 // An action represents one unit of analysis work: the application of
 // one analysis to one package. Actions form a DAG, both within a
 // package (as different analyzers are applied, either in sequence or
@@ -273,12 +283,12 @@ func (act *action) String() string {
 	return fmt.Sprintf("%s@%s", act.a, act.pkg)
 }
 
-func execAll(actions []*action) {
+func execAll(ctx context.Context, actions []*action) {
 	var wg sync.WaitGroup
 	for _, act := range actions {
 		wg.Add(1)
 		work := func(act *action) {
-			act.exec()
+			act.exec(ctx)
 			wg.Done()
 		}
 		go work(act)
@@ -286,11 +296,17 @@ func execAll(actions []*action) {
 	wg.Wait()
 }
 
-func (act *action) exec() { act.once.Do(act.execOnce) }
+func (act *action) exec(ctx context.Context) { act.once.Do(func() { act.execOnce(ctx) }) }
 
-func (act *action) execOnce() {
+func (act *action) execOnce(ctx context.Context) {
 	// Analyze dependencies.
-	execAll(act.deps)
+	execAll(ctx, act.deps)
+
+	// Stop there when the run was cancelled.
+	if err := ctx.Err(); err != nil {
+		act.err = err
+		return
+	}
 
 	// TODO(adonovan): uncomment this during profiling.
 	// It won't build pre-go1.11 but conditional compilation
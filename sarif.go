@@ -0,0 +1,163 @@
+package coi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifSrcRoot = "%SRCROOT%"
+
+	// fingerprintKey names the partial fingerprint of each SARIF result.
+	// Version 2 is derived from Item.Fingerprint, shared with baselines,
+	// and replaces the hash of rule, location and value of version 1.
+	fingerprintKey = "coiFingerprint/v2"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	ShortDescription sarifMessage      `json:"shortDescription"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           sarifRegion      `json:"region"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// ToSARIF writes the report as a SARIF 2.1.0 log. Each configured
+// pattern is a rule and each item a result located relatively to
+// the working directory.
+func (r *Report) ToSARIF(w io.Writer) error {
+	driver := sarifDriver{Name: "coi", InformationURI: "https://github.com/simcap/coi", Rules: []sarifRule{}}
+	index := make(map[string]int)
+	addRule := func(rule Rule) int {
+		if i, ok := index[rule.ID()]; ok {
+			return i
+		}
+		desc := "Literal strings"
+		if rule.Category == "secrets" {
+			desc = "Hard-coded secrets"
+		}
+		if rule.Pattern != "" {
+			desc = fmt.Sprintf("Usage of %s", rule.Pattern)
+		}
+		index[rule.ID()] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               rule.ID(),
+			Name:             rule.Category,
+			ShortDescription: sarifMessage{Text: desc},
+			Properties:       map[string]string{"category": rule.Category},
+		})
+		return index[rule.ID()]
+	}
+	for _, rule := range r.Rules {
+		addRule(rule)
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: []sarifResult{}}
+	if r.WorkingDir != "" {
+		root := url.URL{Scheme: "file", Path: filepath.ToSlash(r.WorkingDir) + "/"}
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLoc{sarifSrcRoot: {URI: root.String()}}
+	}
+
+	occurrences := make(map[string]int)
+	for _, i := range r.Items() {
+		ruleIndex := addRule(i.Rule())
+		loc := sarifArtifactLoc{URI: filepath.ToSlash(i.Position.Filename)}
+		if i.RelativeFilepath != "" {
+			loc = sarifArtifactLoc{URI: filepath.ToSlash(i.RelativeFilepath), URIBaseID: sarifSrcRoot}
+		}
+		fingerprint := i.Fingerprint()
+		occurrences[fingerprint]++
+		level := "note"
+		if i.Category == "secrets" {
+			level = "warning"
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    i.Rule().ID(),
+			RuleIndex: ruleIndex,
+			Level:     level,
+			Message:   sarifMessage{Text: i.Value + i.details()},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: loc,
+					Region:           sarifRegion{StartLine: i.Position.Line, StartColumn: i.Position.Column},
+				},
+			}},
+			PartialFingerprints: map[string]string{
+				fingerprintKey: fmt.Sprintf("%s:%d", fingerprint, occurrences[fingerprint]),
+			},
+		})
+	}
+	run.Tool.Driver = driver
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// fingerprint hashes the given fields into an identifier that does
+// not depend on line numbers, so it survives unrelated edits.
+func fingerprint(fields ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
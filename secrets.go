@@ -0,0 +1,125 @@
+package coi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+)
+
+func FindSecrets(r *Runner) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:     "secrets",
+		Doc:      "Collect hard-coded credentials",
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run:      secretsValues(r),
+	}
+}
+
+func secretsValues(r *Runner) func(*analysis.Pass) (interface{}, error) {
+	return func(pass *analysis.Pass) (interface{}, error) {
+		walkStrings(pass, func(e ast.Expr, value string, stack []ast.Node) {
+			kind := detectSecret(value)
+			if kind == "" {
+				return
+			}
+			msg := "secret (" + kind + "): " + redact(value)
+			pass.Report(analysis.Diagnostic{
+				Category: "secrets",
+				Pos:      e.Pos(),
+				Message:  msg,
+			})
+			r.report(pass, stack, Item{Category: "secrets", Kind: kind, Hash: r.secretHash(value), Value: msg, Position: pass.Fset.Position(e.Pos())})
+		})
+
+		return nil, nil
+	}
+}
+
+// secretFormats are the known formats of credentials, recorded
+// in Item.Kind when they match.
+var secretFormats = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"private-key", regexp.MustCompile(`-----BEGIN ([A-Z]+ )*PRIVATE KEY( BLOCK)?-----`)},
+	{"aws-access-key", regexp.MustCompile(`\b(AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`)},
+	{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"github-token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"gitlab-token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"stripe-key", regexp.MustCompile(`\b[sr]k_live_[A-Za-z0-9]{20,}\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{"connection-string", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^:/?#\s@]+:[^/?#\s@]+@`)},
+}
+
+var (
+	tokenRegexp = regexp.MustCompile(`^[A-Za-z0-9+/=_.-]{20,}$`)
+	digitRegexp = regexp.MustCompile(`[0-9]`)
+	alphaRegexp = regexp.MustCompile(`[A-Za-z]`)
+)
+
+// minSecretEntropy is the Shannon entropy, in bits per character,
+// above which a token without known format is a likely credential.
+const minSecretEntropy = 4.0
+
+// detectSecret returns the kind of credential value looks like, or an
+// empty string. Values of no known format are scored on their entropy
+// when they look like a single token of letters and digits.
+func detectSecret(value string) string {
+	for _, f := range secretFormats {
+		if f.re.MatchString(value) {
+			return f.kind
+		}
+	}
+	if tokenRegexp.MatchString(value) && digitRegexp.MatchString(value) && alphaRegexp.MatchString(value) &&
+		classify(value) == "" && entropy(value) >= minSecretEntropy {
+		return "high-entropy"
+	}
+	return ""
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	var n int
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// redact hides a secret value, only keeping a few leading characters
+// of long values to help recognising it.
+func redact(value string) string {
+	if len(value) < 16 {
+		return "****"
+	}
+	return value[:4] + strings.Repeat("*", 8)
+}
+
+// secretHash returns an HMAC of a secret value keyed with the secrets
+// salt, or the module path by default, so that the value can be
+// deduplicated and baselined without being disclosed. As the module
+// path is public, a private salt is needed for shared reports.
+func (r *Runner) secretHash(value string) string {
+	salt := r.secretsSalt
+	if salt == "" {
+		salt = r.module
+	}
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
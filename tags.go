@@ -0,0 +1,245 @@
+package coi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// namingTagKeys are the tag keys naming a field in a wire format,
+// whose names are compared to spot inconsistencies.
+var namingTagKeys = map[string]bool{
+	"json": true, "yaml": true, "xml": true, "toml": true, "bson": true,
+	"db": true, "mapstructure": true, "form": true, "msgpack": true,
+}
+
+func FindTags(r *Runner) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:     "tags",
+		Doc:      "Collect struct tags",
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run:      tagsValues(r),
+	}
+}
+
+func tagsValues(r *Runner) func(*analysis.Pass) (interface{}, error) {
+	return func(pass *analysis.Pass) (interface{}, error) {
+		inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+		inspect.WithStack([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if !push {
+				return true
+			}
+			owner := pass.Pkg.Path() + "." + structName(pass.Fset, stack)
+			for _, field := range n.(*ast.StructType).Fields.List {
+				if field.Tag == nil {
+					continue
+				}
+				tag, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					continue
+				}
+				entries := parseTag(tag)
+				for _, name := range fieldNames(field) {
+					symbol := owner + "." + name
+					for _, e := range entries {
+						if !r.keepTag(e.key) {
+							continue
+						}
+						item := Item{
+							Category:     "tags",
+							Pattern:      e.key,
+							Symbol:       symbol,
+							TagKey:       e.key,
+							TagName:      e.name(),
+							TagOptions:   e.options(),
+							Inconsistent: inconsistentNames(e, entries, name),
+							Value:        fmt.Sprintf("%s %s:%q", symbol, e.key, e.value),
+							Position:     pass.Fset.Position(field.Tag.Pos()),
+						}
+						pass.Report(analysis.Diagnostic{
+							Pos:      field.Tag.Pos(),
+							Category: "tags",
+							Message:  item.Value + item.details(),
+						})
+						r.report(pass, stack, item)
+					}
+				}
+			}
+			return true
+		})
+
+		return nil, nil
+	}
+}
+
+// keepTag reports whether tags with the key are collected.
+func (r *Runner) keepTag(key string) bool {
+	for _, k := range r.tags {
+		if k == "*" || k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// tagEntry is a key and its value in a struct tag.
+type tagEntry struct {
+	key, value string
+}
+
+func (e tagEntry) name() string {
+	name, _, _ := strings.Cut(e.value, ",")
+	return name
+}
+
+func (e tagEntry) options() []string {
+	_, opts, ok := strings.Cut(e.value, ",")
+	if !ok {
+		return nil
+	}
+	return strings.Split(opts, ",")
+}
+
+// parseTag returns the key:"value" pairs of a struct tag in order,
+// with the semantics of reflect.StructTag.Lookup: parsing stops at
+// the first malformed pair.
+func parseTag(tag string) []tagEntry {
+	var entries []tagEntry
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		quoted := tag[:i+1]
+		tag = tag[i+1:]
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			break
+		}
+		entries = append(entries, tagEntry{key, value})
+	}
+	return entries
+}
+
+// inconsistentNames returns the other naming keys of the tag giving the
+// field a name different from e, as key=name. Keys without name use the
+// name of the field.
+func inconsistentNames(e tagEntry, entries []tagEntry, field string) []string {
+	name := tagName(e, field)
+	if !namingTagKeys[e.key] || name == "-" {
+		return nil
+	}
+	var others []string
+	for _, o := range entries {
+		if other := tagName(o, field); o.key != e.key && namingTagKeys[o.key] && other != "-" && other != name {
+			others = append(others, o.key+"="+other)
+		}
+	}
+	sort.Strings(others)
+	return others
+}
+
+// tagName returns the name given to the field by the tag entry,
+// the field name when the entry does not name it.
+func tagName(e tagEntry, field string) string {
+	if name := e.name(); name != "" {
+		return name
+	}
+	return field
+}
+
+// structName returns the name of the type or variable declaring the
+// struct at the top of the stack, nested structs being named after
+// their field and other anonymous structs after their line, such as
+// "struct@12". Structs declared in a function are prefixed with its
+// name.
+func structName(fset *token.FileSet, stack []ast.Node) string {
+	var names []string
+	outer := stack[len(stack)-1]
+	base := ""
+walk:
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case *ast.StructType:
+			outer = n
+		case *ast.Field:
+			// Struct fields, not parameters.
+			if _, ok := stack[i-2].(*ast.StructType); ok && len(n.Names) > 0 {
+				names = append(names, n.Names[0].Name)
+			}
+		case *ast.TypeSpec:
+			base = n.Name.Name
+			break walk
+		case *ast.ValueSpec:
+			base = n.Names[0].Name
+			break walk
+		case *ast.FuncType, *ast.BlockStmt:
+			break walk
+		}
+	}
+	if base == "" {
+		base = fmt.Sprintf("struct@%d", fset.Position(outer.Pos()).Line)
+	}
+	names = append(names, base)
+	if fn := enclosingFunction(stack); fn != "" {
+		names = append(names, fn)
+	}
+	for l, r := 0, len(names)-1; l < r; l, r = l+1, r-1 {
+		names[l], names[r] = names[r], names[l]
+	}
+	return strings.Join(names, ".")
+}
+
+// fieldNames returns the names of a struct field, the type name for
+// embedded fields.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		t := field.Type
+		if star, ok := t.(*ast.StarExpr); ok {
+			t = star.X
+		}
+		if sel, ok := t.(*ast.SelectorExpr); ok {
+			return []string{sel.Sel.Name}
+		}
+		return []string{types.ExprString(t)}
+	}
+	var names []string
+	for _, n := range field.Names {
+		names = append(names, n.Name)
+	}
+	return names
+}
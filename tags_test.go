@@ -0,0 +1,32 @@
+package coi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tcases := []struct {
+		tag string
+		exp []tagEntry
+	}{
+		{`json:"name,omitempty" yaml:"name"`, []tagEntry{{"json", "name,omitempty"}, {"yaml", "name"}}},
+		{` validate:"min=1,max=\"10\""  `, []tagEntry{{"validate", `min=1,max="10"`}}},
+		{`json:"id" bad db:"id"`, []tagEntry{{"json", "id"}}},
+		{`json:name`, nil},
+		{``, nil},
+	}
+	for _, tc := range tcases {
+		got := parseTag(tc.tag)
+		if !reflect.DeepEqual(got, tc.exp) {
+			t.Errorf("parseTag(%q) = %v, want %v", tc.tag, got, tc.exp)
+			continue
+		}
+		// Values must be the same as with reflect.
+		for _, e := range got {
+			if v, _ := reflect.StructTag(tc.tag).Lookup(e.key); v != e.value {
+				t.Errorf("%q: got %s value %q, reflect has %q", tc.tag, e.key, e.value, v)
+			}
+		}
+	}
+}
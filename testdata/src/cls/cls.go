@@ -0,0 +1,8 @@
+package cls
+
+const (
+	api   = "https://api.example.com" // want `string: "https://api.example.com" \(url\)`
+	query = "SELECT * FROM users"     // want `string: "SELECT \* FROM users" \(sql\)`
+	home  = "/home/user"
+	name  = "coi"
+)
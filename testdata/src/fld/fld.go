@@ -0,0 +1,28 @@
+package fld
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+type request struct {
+	*http.Request
+}
+
+type endpoint struct {
+	host string
+	port int
+}
+
+const insecure = true
+
+func fields(req *http.Request, r request) {
+	_ = req.Header                                // want `net/http.Request.Header \(read\)`
+	req.Header = nil                              // want `net/http.Request.Header \(write\)`
+	r.Header.Set("k", "v")                        // want `net/http.Request.Header \(read\)`
+	_ = &tls.Config{InsecureSkipVerify: insecure} // want `crypto/tls.Config.InsecureSkipVerify = true \(init\)`
+	c := tls.Config{ServerName: "example.com"}    // want `crypto/tls.Config.ServerName = "example.com" \(init\)`
+	c.InsecureSkipVerify = !insecure              // want `crypto/tls.Config.InsecureSkipVerify = false \(write\)`
+	c.MinVersion++                                // want `crypto/tls.Config.MinVersion \(write\)`
+	_ = endpoint{"localhost", 80}                 // want `fld.endpoint.host = "localhost" \(init\)`
+}
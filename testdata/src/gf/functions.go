@@ -0,0 +1,9 @@
+package gf
+
+import "os"
+
+func files() {
+	os.ReadFile("a")              // want `os.ReadFile\("a"\)`
+	os.WriteFile("b", nil, 0o644) // want `os.WriteFile\("b", nil, 0o644\)`
+	os.Remove("c")
+}
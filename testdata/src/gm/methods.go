@@ -0,0 +1,17 @@
+package gm
+
+import (
+	"bytes"
+	"net/http"
+)
+
+func headers(h http.Header) {
+	h.Set("k", "v") // want `net/http.Header.Set\("k", "v"\)`
+	h.Values("k")   // want `net/http.Header.Values\("k"\)`
+	h.Get("k")
+}
+
+func buffers(b *bytes.Buffer) {
+	b.WriteString("s") // want `bytes.Buffer.WriteString\("s"\)`
+	b.Len()
+}
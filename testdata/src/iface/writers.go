@@ -0,0 +1,15 @@
+package iface
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+func write(w io.Writer, rw io.ReadWriter, f *os.File, b bytes.Buffer) {
+	w.Write(nil)  // want `io.Writer.Write\(nil\)$`
+	rw.Write(nil) // want `io.Writer.Write\(nil\)$`
+	f.Write(nil)  // want `os.File.Write\(nil\) via io.Writer`
+	b.Write(nil)  // want `bytes.Buffer.Write\(nil\) via io.Writer`
+	f.Close()
+}
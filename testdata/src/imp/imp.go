@@ -0,0 +1,14 @@
+package imp
+
+import (
+	_ "embed" // want `blank import of embed: imp -> embed`
+	"encoding/json"
+	"net/http"  // want `direct import of net/http: imp -> net/http` `transitive import of crypto/tls: imp -> net/http -> crypto/tls`
+	. "strings" // want `dot import of strings: imp -> strings`
+)
+
+var (
+	_ = json.Valid
+	_ = http.Get
+	_ = ToUpper
+)
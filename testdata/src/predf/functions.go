@@ -0,0 +1,22 @@
+package predf
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+)
+
+const tool = "ls"
+
+func run(name string, w http.ResponseWriter, err error) {
+	exec.Command("ls")
+	exec.Command(tool)
+	exec.Command(name)             // want `^os/exec.Command\(name\)$`
+	http.Get("http://example.com") // want `^net/http.Get\("http://example.com"\)$`
+	http.Get("https://example.com")
+	fmt.Fprintf(w, "%v", err) // want `^fmt.Fprintf\(w, "%v", err\)$`
+	fmt.Fprintf(os.Stdout, "%v", err)
+	cmd := exec.Command
+	cmd(name)
+}
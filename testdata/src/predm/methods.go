@@ -0,0 +1,12 @@
+package predm
+
+import "database/sql"
+
+const query = "SELECT 1"
+
+func queries(db *sql.DB, q string) {
+	db.Query(query)
+	db.Query(q) // want `^database/sql.DB.Query\(q\)$`
+	(*sql.DB).Query(db, query)
+	(*sql.DB).Query(db, q) // want `^database/sql.DB.Query\(db, q\)$`
+}
@@ -0,0 +1,27 @@
+package pref
+
+import (
+	"crypto/tls"
+	"net/http"
+	"os"
+)
+
+type client struct {
+	http.Client // want `net/http.Client \(type\)`
+
+	fallback *http.Client // want `net/http.Client \(type\)`
+}
+
+func refs(c *client) {
+	_ = &tls.Config{ // want `crypto/tls.Config \(type\)`
+		InsecureSkipVerify: true, // want `crypto/tls.Config.InsecureSkipVerify \(field\)`
+	}
+	_ = http.DefaultClient              // want `net/http.DefaultClient \(var\)`
+	_ = os.Args                         // want `os.Args \(var\)`
+	_ = http.MethodGet                  // want `net/http.MethodGet \(const\)`
+	_ = c.Timeout                       // want `net/http.Client.Timeout \(field\)`
+	c.Get("/")                          // want `net/http.Client.Get\("/"\)`
+	_ = os.Getenv                       // want `os.Getenv \(func\)`
+	_ = os.Getenv("HOME")               // want `os.Getenv\("HOME"\)`
+	_ = c.fallback.CloseIdleConnections // want `net/http.Client.CloseIdleConnections \(method\)`
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/simcap/coi/testdata/src/reach"
+
+func main() {
+	reach.Run("main")
+}
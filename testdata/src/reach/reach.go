@@ -0,0 +1,26 @@
+package reach
+
+import "os/exec"
+
+func init() {
+	run("init")
+}
+
+// Run is an exported entry point.
+func Run(name string) error {
+	return run(name)
+}
+
+type Tool struct{}
+
+func (Tool) Start() error {
+	return exec.Command("true").Start()
+}
+
+func run(name string) error {
+	return exec.Command(name).Run()
+}
+
+func unused() {
+	exec.Command("unused")
+}
@@ -0,0 +1,3 @@
+package reachbad
+
+var broken int = "not an int"
@@ -0,0 +1,8 @@
+package reachbad
+
+import "os/exec"
+
+// Run is an exported entry point.
+func Run(name string) error {
+	return exec.Command(name).Run()
+}
@@ -0,0 +1,15 @@
+package reff
+
+import (
+	"net/http"
+	"os"
+)
+
+var readFile = os.ReadFile // want `^os.ReadFile$`
+
+func handlers() {
+	http.HandleFunc("/", http.NotFound) // want `^net/http.NotFound$`
+	os.ReadFile("x")                    // want `^os.ReadFile\("x"\)$`
+	(os.ReadFile)("y")                  // want `^os.ReadFile\("y"\)$`
+	readFile("z")
+}
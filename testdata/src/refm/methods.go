@@ -0,0 +1,17 @@
+package refm
+
+import (
+	"database/sql"
+	"net/http"
+)
+
+type server struct{}
+
+func (s *server) serve(w http.ResponseWriter, r *http.Request) {}
+
+func refs(db *sql.DB, s *server) {
+	http.HandleFunc("/", s.serve) // want `^refm.server.serve$`
+	exec := (*sql.DB).Exec        // want `^database/sql.DB.Exec$`
+	db.Exec("q")                  // want `^database/sql.DB.Exec\("q"\)$`
+	exec(db, "q")
+}
@@ -0,0 +1,31 @@
+package role
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+)
+
+const prefix = "app" // want `string: "app" \[const prefix\]`
+
+type config struct {
+	Name string `json:"name"` // want `string: "json:\\"name\\"" \[struct tag\]`
+}
+
+var labels = map[string]string{
+	"env": "prod", // want `string: "env" \[map key\]` `string: "prod" \[map value\]`
+}
+
+func roles() (string, error) {
+	_ = config{Name: "coi"}       // want `string: "coi" \[field Name\]`
+	home := os.Getenv("HOME_DIR") // want `string: "HOME_DIR" \(env\) \[arg 0 of os.Getenv\]`
+	home = prefix + "-home"       // want `string: "app-home" \[assign home\]`
+	log.Printf("home %s", home)   // want `string: "home %s" \[log\]`
+	if home == "" {
+		return "", errors.New("no home") // want `string: "no home" \[error\]`
+	}
+	_ = fmt.Errorf("bad %s", home) // want `string: "bad %s" \[error\]`
+	_ = fmt.Errorf("bad %s", "x")  // want `string: "bad %s" \[error\]` `string: "x" \[arg 1 of fmt.Errorf\]`
+	return ("done"), nil           // want `string: "done" \[return\]`
+}
@@ -0,0 +1,21 @@
+package s
+
+import "strings"
+
+type path string
+
+const base = "https://example.com" // want `string: "https://example.com"`
+
+const users path = "users" // want `string: "users"`
+
+var (
+	endpoint = base + "/v1/" + string(users) // want `string: "https://example.com/v1/users"`
+	raw      = `C:\temp`                     // want `string: "C:\\\\temp"`
+	typed    = path("/tmp") + "/" + users    // want `string: "/tmp/users"`
+	notConst = strings.ToUpper(base)
+	empty    = ""
+)
+
+type tagged struct {
+	Name string `json:"name"` // want `string: "json:\\"name\\""`
+}
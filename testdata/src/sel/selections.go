@@ -0,0 +1,20 @@
+package sel
+
+import "net/http"
+
+type Headers = http.Header
+
+type wrapper struct{ http.Header }
+
+type List[T any] struct{ items []T }
+
+func (l *List[T]) Push(v T) { l.items = append(l.items, v) }
+
+func calls(h *http.Header, a Headers, w wrapper, l *List[int], s List[string]) {
+	h.Set("k", "v") // want `^net/http.Header.Set\("k", "v"\)$`
+	a.Set("k", "v") // want `^net/http.Header.Set\("k", "v"\)$`
+	w.Set("k", "v") // want `^net/http.Header.Set\("k", "v"\)$`
+	l.Push(1)       // want `^sel.List.Push\(1\)$`
+	s.Push("x")     // want `^sel.List.Push\("x"\)$`
+	h.Get("k")
+}
@@ -0,0 +1,33 @@
+package tags
+
+import "net/http"
+
+type User struct {
+	ID       int    `json:"id" db:"user_id"`                                // want `tags.User.ID json:"id" inconsistent with db=user_id` `tags.User.ID db:"user_id" inconsistent with json=id`
+	Name     string `json:"name,omitempty" yaml:"name" validate:"required"` // want `tags.User.Name json:"name,omitempty"` `tags.User.Name yaml:"name"`
+	Password string `json:"-"`                                              // want `tags.User.Password json:"-"`
+	Address  struct {
+		City string `json:"city"` // want `tags.User.Address.City json:"city"`
+	} `json:"address"` // want `tags.User.Address json:"address"`
+	*http.Client `json:"client,inline"` // want `tags.User.Client json:"client,inline"`
+	Ignored      string                 `env:"IGNORED"`
+	Malformed    string                 `json:name`
+	Nickname     string                 `json:",omitempty" yaml:"nick"` // want `tags.User.Nickname json:",omitempty" inconsistent with yaml=nick` `tags.User.Nickname yaml:"nick" inconsistent with json=Nickname`
+}
+
+var defaults = struct {
+	Port int `json:"port"` // want `tags.defaults.Port json:"port"`
+}{}
+
+func load() {
+	var cfg struct {
+		Host string `json:"host"` // want `tags.load.cfg.Host json:"host"`
+	}
+	_ = cfg
+	_ = []struct {
+		Host string `json:"host"` // want `tags.load.struct@27.Host json:"host"`
+	}{}
+	_ = struct {
+		Host string `json:"host"` // want `tags.load.struct@30.Host json:"host"`
+	}{}
+}
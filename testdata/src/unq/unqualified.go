@@ -0,0 +1,20 @@
+package unq
+
+import (
+	. "os"
+	"strings"
+)
+
+func Exec(query string) {}
+
+func Map[T any](v T) T { return v }
+
+func calls() {
+	ReadFile("a") // want `^os.ReadFile\("a"\)$`
+	Exec("q")     // want `^unq.Exec\("q"\)$`
+	exec := Exec  // want `^unq.Exec$`
+	Map[int](1)   // want `^unq.Map\(1\)$`
+	Map("s")      // want `^unq.Map\("s"\)$`
+	strings.ToUpper("x")
+	exec("q")
+}